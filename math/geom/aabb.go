@@ -0,0 +1,94 @@
+package geom
+
+import (
+	"math"
+)
+
+// AABB is an axis-aligned bounding box, given by its minimum and maximum
+// corner. The box is closed, points on its boundary are inside. A box with Min
+// greater than Max on any axis is empty and contains nothing.
+type AABB struct {
+
+	// Min is the corner with the smallest coordinates.
+	Min Vec3
+
+	// Max is the corner with the largest coordinates.
+	Max Vec3
+}
+
+// Empty returns true if the box contains no points.
+func (b *AABB) Empty() bool {
+	return b.Min[0] > b.Max[0] || b.Min[1] > b.Max[1] || b.Min[2] > b.Max[2]
+}
+
+// Contains returns true if the point lies inside the box or on its boundary.
+func (b *AABB) Contains(p *Vec3) bool {
+	for i := 0; i < 3; i++ {
+		if p[i] < b.Min[i] || p[i] > b.Max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Intersects returns true if the two boxes share at least one point. Boxes
+// that only touch on their boundary intersect.
+func (b *AABB) Intersects(other *AABB) bool {
+	if b.Empty() || other.Empty() {
+		return false
+	}
+	for i := 0; i < 3; i++ {
+		if b.Max[i] < other.Min[i] || other.Max[i] < b.Min[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Union returns a new box that is the smallest box containing both boxes. An
+// empty box does not contribute to the union.
+func (b *AABB) Union(other *AABB) *AABB {
+	if b.Empty() {
+		u := *other
+		return &u
+	}
+	if other.Empty() {
+		u := *b
+		return &u
+	}
+	u := AABB{}
+	for i := 0; i < 3; i++ {
+		u.Min[i] = math.Min(b.Min[i], other.Min[i])
+		u.Max[i] = math.Max(b.Max[i], other.Max[i])
+	}
+	return &u
+}
+
+// Transform returns a new box that tightly bounds the box after transforming
+// it with the matrix. All 8 corners are transformed and the minimum and
+// maximum are taken again, so for rotations the new box is usually larger
+// than the transformed geometry. An empty box stays empty.
+func (b *AABB) Transform(m *Mat4) *AABB {
+	if b.Empty() {
+		t := *b
+		return &t
+	}
+	t := AABB{
+		Vec3{math.Inf(1), math.Inf(1), math.Inf(1)},
+		Vec3{math.Inf(-1), math.Inf(-1), math.Inf(-1)},
+	}
+	for c := 0; c < 8; c++ {
+		p := Vec4{b.Min[0], b.Min[1], b.Min[2], 1}
+		for i := 0; i < 3; i++ {
+			if c&(1<<uint(i)) != 0 {
+				p[i] = b.Max[i]
+			}
+		}
+		q := m.Transf(&p).ToVec3()
+		for i := 0; i < 3; i++ {
+			t.Min[i] = math.Min(t.Min[i], q[i])
+			t.Max[i] = math.Max(t.Max[i], q[i])
+		}
+	}
+	return &t
+}
@@ -0,0 +1,94 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+var containstests = []struct {
+	p  Vec3
+	in bool
+}{
+	{Vec3{0.5, 0.5, 0.5}, true},
+	{Vec3{1, 0.5, 0}, true},
+	{Vec3{0, 0, 0}, true},
+	{Vec3{1.01, 0.5, 0.5}, false},
+	{Vec3{0.5, -0.01, 0.5}, false},
+}
+
+func TestContains(t *testing.T) {
+	b := AABB{Vec3{0, 0, 0}, Vec3{1, 1, 1}}
+	for _, test := range containstests {
+		in := b.Contains(&test.p)
+		if in != test.in {
+			t.Errorf("%v: expected '%v' but got '%v'", test.p, test.in, in)
+		}
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	e := AABB{Vec3{1, 0, 0}, Vec3{0, 1, 1}}
+	if !e.Empty() {
+		t.Errorf("expected '%v' but got '%v'", true, e.Empty())
+	}
+	p := Vec3{0.5, 0.5, 0.5}
+	if e.Contains(&p) {
+		t.Errorf("expected '%v' but got '%v'", false, true)
+	}
+	b := AABB{Vec3{0, 0, 0}, Vec3{1, 1, 1}}
+	if e.Intersects(&b) {
+		t.Errorf("expected '%v' but got '%v'", false, true)
+	}
+	u := *e.Union(&b)
+	if u != b {
+		t.Errorf("expected '%v' but got '%v'", b, u)
+	}
+}
+
+var intersectstests = []struct {
+	b     AABB
+	inter bool
+}{
+	{AABB{Vec3{0.5, 0.5, 0.5}, Vec3{2, 2, 2}}, true},
+	{AABB{Vec3{1, 0, 0}, Vec3{2, 1, 1}}, true},
+	{AABB{Vec3{1.5, 0, 0}, Vec3{2, 1, 1}}, false},
+	{AABB{Vec3{0, 0, -3}, Vec3{1, 1, -1}}, false},
+}
+
+func TestIntersects(t *testing.T) {
+	b := AABB{Vec3{0, 0, 0}, Vec3{1, 1, 1}}
+	for _, test := range intersectstests {
+		i := b.Intersects(&test.b)
+		if i != test.inter {
+			t.Errorf("%v: expected '%v' but got '%v'", test.b, test.inter, i)
+		}
+		if j := test.b.Intersects(&b); j != i {
+			t.Errorf("%v: expected symmetric result '%v' but got '%v'", test.b, i, j)
+		}
+	}
+}
+
+func TestUnion(t *testing.T) {
+	b := AABB{Vec3{0, 0, 0}, Vec3{1, 1, 1}}
+	c := AABB{Vec3{-1, 0.5, 0.5}, Vec3{0.5, 3, 0.7}}
+	u := *b.Union(&c)
+	r := AABB{Vec3{-1, 0, 0}, Vec3{1, 3, 1}}
+	if u != r {
+		t.Errorf("expected '%v' but got '%v'", r, u)
+	}
+}
+
+func TestAABBTransform(t *testing.T) {
+	b := AABB{Vec3{-1, -1, -1}, Vec3{1, 1, 1}}
+	m := TranslationMat(5, 0, 0)
+	m.Mul(RotZMat(math.Pi / 4))
+	a := b.Transform(m)
+	s := math.Sqrt2
+	r := AABB{Vec3{5 - s, -s, -1}, Vec3{5 + s, s, 1}}
+	for i := 0; i < 3; i++ {
+		if math.Abs(a.Min[i]-r.Min[i]) > eps || math.Abs(a.Max[i]-r.Max[i]) > eps {
+			t.Errorf("expected '%v' but got '%v'", r, *a)
+			break
+		}
+	}
+}
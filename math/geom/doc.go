@@ -1,2 +1,7 @@
 // Package geom provides geometry math.
+//
+// All floating point vector and matrix types use float64 components. There
+// are no generic float32 variants since the package still builds with Go
+// versions that lack type parameters; convert at the boundary where float32
+// data is required, e.g. when uploading to the GPU.
 package geom
@@ -0,0 +1,93 @@
+package geom
+
+import (
+	"errors"
+	"math"
+)
+
+// DualQuat is a unit dual quaternion representing a rigid transformation, a
+// rotation followed by a translation. Blending dual quaternions instead of
+// matrices avoids the loss of volume (candy-wrapper artifacts) in skinning.
+type DualQuat struct {
+
+	// Real is the rotation as a unit quaternion.
+	Real Quat
+
+	// Dual encodes the translation t as t·Real/2, where t is a quaternion with
+	// vector part t and scalar part 0.
+	Dual Quat
+}
+
+// conj returns a new quaternion that is the conjugate of q, negating the
+// vector part.
+func conj(q *Quat) *Quat {
+	return &Quat{-q[0], -q[1], -q[2], q[3]}
+}
+
+// DualQuatFromMat4 returns a new dual quaternion with the same transformation
+// as the matrix, which must be a rotation followed by a translation without
+// scale or shear.
+func DualQuatFromMat4(m *Mat4) *DualQuat {
+	r := QuatFromMat4(m)
+	d := Quat{m[3], m[7], m[11], 0}
+	d.Mul(r)
+	for i := range d {
+		d[i] /= 2
+	}
+	return &DualQuat{*r, d}
+}
+
+// Normalize normalizes the dual quaternion so its real part is of unit
+// length. A dual quaternion with a real part of length 0 is left unchanged.
+func (d *DualQuat) Normalize() {
+	r := &d.Real
+	l := math.Sqrt(r[0]*r[0] + r[1]*r[1] + r[2]*r[2] + r[3]*r[3])
+	if l == 0 {
+		return
+	}
+	for i := 0; i < 4; i++ {
+		d.Real[i] /= l
+		d.Dual[i] /= l
+	}
+}
+
+// BlendDualQuats returns a new dual quaternion that is the normalized
+// weighted sum of the given ones, e.g. the bone transformations influencing a
+// vertex. As with quaternions, q and -q represent the same transformation, so
+// dual quaternions with a real part pointing away from the first one are
+// negated to blend along the shorter path. It returns an error if the number
+// of weights does not match or the weighted sum is zero.
+func BlendDualQuats(ds []DualQuat, weights []float64) (*DualQuat, error) {
+	if len(ds) != len(weights) {
+		return nil, errors.New("Number of dual quaternions and weights must match")
+	}
+	b := DualQuat{}
+	for k := range ds {
+		w := weights[k]
+		r0, rk := &ds[0].Real, &ds[k].Real
+		if r0[0]*rk[0]+r0[1]*rk[1]+r0[2]*rk[2]+r0[3]*rk[3] < 0 {
+			w = -w
+		}
+		for i := 0; i < 4; i++ {
+			b.Real[i] += w * ds[k].Real[i]
+			b.Dual[i] += w * ds[k].Dual[i]
+		}
+	}
+	if b.Real == (Quat{}) {
+		return nil, errors.New("Weighted sum of dual quaternions is zero")
+	}
+	b.Normalize()
+	return &b, nil
+}
+
+// ToMat4 returns a new matrix with the same transformation as the dual
+// quaternion, which must be normalized.
+func (d *DualQuat) ToMat4() *Mat4 {
+	t := d.Dual
+	t.Mul(conj(&d.Real))
+	m := d.Real.ToMat4()
+	m[3] = 2 * t[0]
+	m[7] = 2 * t[1]
+	m[11] = 2 * t[2]
+	return m
+}
@@ -0,0 +1,50 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDualQuatToMat4(t *testing.T) {
+	for _, test := range quattests {
+		m := Mul(TranslationMat(1, -2, 3), RotAxisMat(&test.axis, test.rad))
+		n := DualQuatFromMat4(m).ToMat4()
+		if !n.ApproxEq(m, eps) {
+			t.Errorf("axis %v: expected '%v' but got '%v'", test.axis, *m, *n)
+		}
+	}
+}
+
+func TestBlendDualQuats(t *testing.T) {
+	axis := Vec3{0, 0, 1}
+	a := DualQuatFromMat4(Mul(TranslationMat(0, 0, 0), RotAxisMat(&axis, 0)))
+	b := DualQuatFromMat4(Mul(TranslationMat(0, 0, 2), RotAxisMat(&axis, math.Pi/2)))
+	d, err := BlendDualQuats([]DualQuat{*a, *b}, []float64{0.5, 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := d.ToMat4()
+	r := Mul(TranslationMat(0, 0, 1), RotAxisMat(&axis, math.Pi/4))
+	if !m.ApproxEq(r, eps) {
+		t.Errorf("expected '%v' but got '%v'", *r, *m)
+	}
+	// Blending with a negated but equivalent dual quaternion gives the same.
+	for i := 0; i < 4; i++ {
+		b.Real[i] = -b.Real[i]
+		b.Dual[i] = -b.Dual[i]
+	}
+	e, _ := BlendDualQuats([]DualQuat{*a, *b}, []float64{0.5, 0.5})
+	if n := e.ToMat4(); !n.ApproxEq(r, eps) {
+		t.Errorf("expected '%v' but got '%v'", *r, *n)
+	}
+}
+
+func TestBlendDualQuatsInvalid(t *testing.T) {
+	a := DualQuatFromMat4(IdentityMat())
+	if _, err := BlendDualQuats([]DualQuat{*a}, []float64{1, 2}); err == nil {
+		t.Errorf("expected error for mismatched lengths")
+	}
+	if _, err := BlendDualQuats([]DualQuat{*a}, []float64{0}); err == nil {
+		t.Errorf("expected error for zero weights")
+	}
+}
@@ -0,0 +1,54 @@
+package geom
+
+import (
+	"strconv"
+	"strings"
+)
+
+// formatFloat formats a component in the shortest representation that
+// round-trips.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// formatVec formats the components of a vector as (x, y, ...).
+func formatVec(c []float64) string {
+	s := make([]string, len(c))
+	for i := range c {
+		s[i] = formatFloat(c[i])
+	}
+	return "(" + strings.Join(s, ", ") + ")"
+}
+
+// String returns the vector in the form (x, y, z).
+func (v Vec3) String() string {
+	return formatVec(v[:])
+}
+
+// String returns the vector in the form (x, y, z, w).
+func (v Vec4) String() string {
+	return formatVec(v[:])
+}
+
+// String returns the matrix as a grid of 4 lines, one for each row. The
+// columns are right-aligned to the widest component in each column.
+func (m Mat4) String() string {
+	var cells [16]string
+	var width [4]int
+	for i := range m {
+		cells[i] = formatFloat(m[i])
+		if l := len(cells[i]); l > width[i%4] {
+			width[i%4] = l
+		}
+	}
+	rows := make([]string, 4)
+	for i := 0; i < 4; i++ {
+		row := make([]string, 4)
+		for j := 0; j < 4; j++ {
+			c := cells[i*4+j]
+			row[j] = strings.Repeat(" ", width[j]-len(c)) + c
+		}
+		rows[i] = "[" + strings.Join(row, " ") + "]"
+	}
+	return strings.Join(rows, "\n")
+}
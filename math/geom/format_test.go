@@ -0,0 +1,41 @@
+package geom
+
+import (
+	"fmt"
+	"testing"
+)
+
+var vecstringtests = []struct {
+	v fmt.Stringer
+	s string
+}{
+	{Vec3{1, -2.5, 0}, "(1, -2.5, 0)"},
+	{&Vec3{0.1, 2, 3}, "(0.1, 2, 3)"},
+	{Vec4{1, 2, 3, 1}, "(1, 2, 3, 1)"},
+}
+
+func TestVecString(t *testing.T) {
+	for _, test := range vecstringtests {
+		s := fmt.Sprint(test.v)
+		if s != test.s {
+			t.Errorf("expected '%v' but got '%v'", test.s, s)
+		}
+	}
+}
+
+func TestMat4StringIdentity(t *testing.T) {
+	s := IdentityMat().String()
+	r := "[1 0 0 0]\n[0 1 0 0]\n[0 0 1 0]\n[0 0 0 1]"
+	if s != r {
+		t.Errorf("expected '%v' but got '%v'", r, s)
+	}
+}
+
+func TestMat4String(t *testing.T) {
+	m := TranslationMat(-10, 2.5, 100)
+	s := m.String()
+	r := "[1 0 0 -10]\n[0 1 0 2.5]\n[0 0 1 100]\n[0 0 0   1]"
+	if s != r {
+		t.Errorf("expected '%v' but got '%v'", r, s)
+	}
+}
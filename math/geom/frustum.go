@@ -0,0 +1,70 @@
+package geom
+
+// Frustum is the volume visible through a camera, bounded by 6 planes in the
+// order left, right, bottom, top, near and far. The plane normals point to
+// the inside of the frustum.
+type Frustum [6]Plane
+
+// FrustumFromMat4 returns the frustum of a combined view and projection
+// matrix in world coordinates, assuming the OpenGL convention of normalized
+// device coordinates in [-1,1] as produced by PerspectiveMat and OrthoMat.
+//
+// The planes are extracted from the rows of the matrix (Gribb/Hartmann
+// method): a point p is inside the left plane if its clip coordinates satisfy
+// -w <= x, i.e. (row3 + row0)·p >= 0, and similarly for the other planes. The
+// planes are normalized so Plane.Distance returns actual distances.
+func FrustumFromMat4(viewProj *Mat4) *Frustum {
+	m := viewProj
+	f := Frustum{}
+	for i := 0; i < 6; i++ {
+		r := i / 2
+		s := 1.0
+		if i%2 == 1 {
+			s = -1
+		}
+		n := Vec3{
+			m[12] + s*m[r*4],
+			m[13] + s*m[r*4+1],
+			m[14] + s*m[r*4+2],
+		}
+		d := m[15] + s*m[r*4+3]
+		if l := n.Len(); l != 0 {
+			n.Scale(1 / l)
+			d /= l
+		}
+		f[i] = Plane{n, d}
+	}
+	return &f
+}
+
+// ContainsSphere returns true if the sphere with the given center and radius
+// lies at least partly inside the frustum. The test is conservative: spheres
+// near the corners outside of the frustum may still be reported as inside.
+func (f *Frustum) ContainsSphere(center *Vec3, radius float64) bool {
+	for i := range f {
+		if f[i].Distance(center) < -radius {
+			return false
+		}
+	}
+	return true
+}
+
+// FrustumCorners returns the 8 corners of the frustum in world coordinates,
+// given the inverse of the combined view and projection matrix. The corners of
+// the cube [-1,1] in normalized device coordinates are transformed back into
+// world coordinates. The index of a corner encodes its position: bit 0 is set
+// for x = 1 (right), bit 1 for y = 1 (top) and bit 2 for z = 1 (far), so
+// index 0 is the near bottom left and index 7 the far top right corner.
+func FrustumCorners(invViewProj *Mat4) [8]Vec3 {
+	var c [8]Vec3
+	for i := range c {
+		p := Vec4{-1, -1, -1, 1}
+		for j := 0; j < 3; j++ {
+			if i&(1<<uint(j)) != 0 {
+				p[j] = 1
+			}
+		}
+		c[i] = *invViewProj.Transf(&p).ToVec3()
+	}
+	return c
+}
@@ -0,0 +1,69 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+var containsspheretests = []struct {
+	c  Vec3
+	r  float64
+	in bool
+}{
+	{Vec3{0, 0, -10}, 1, true},
+	{Vec3{0, 0, 10}, 1, false},
+	{Vec3{0, 0, -0.5}, 1, true},
+	{Vec3{0, 0, -150}, 1, false},
+	{Vec3{0, 0, -150}, 60, true},
+	{Vec3{-30, 0, -10}, 1, false},
+	{Vec3{12, 0, -10}, 3, true},
+}
+
+func TestContainsSphere(t *testing.T) {
+	m := PerspectiveMat(math.Pi/2, 1, 1, 100)
+	m.Mul(LookAt(&Vec3{0, 0, 0}, &Vec3{0, 0, -1}, &Vec3{0, 1, 0}))
+	f := FrustumFromMat4(m)
+	for _, test := range containsspheretests {
+		in := f.ContainsSphere(&test.c, test.r)
+		if in != test.in {
+			t.Errorf("%v,%v: expected '%v' but got '%v'", test.c, test.r, test.in, in)
+		}
+	}
+}
+
+func TestFrustumFromMat4(t *testing.T) {
+	f := FrustumFromMat4(PerspectiveMat(math.Pi/2, 1, 1, 100))
+	near := Plane{Vec3{0, 0, -1}, -1}
+	far := Plane{Vec3{0, 0, 1}, 100}
+	for i, r := range []Plane{near, far} {
+		p := f[4+i]
+		n := Vec3{p.Normal[0] - r.Normal[0], p.Normal[1] - r.Normal[1], p.Normal[2] - r.Normal[2]}
+		if n.Len() > eps || math.Abs(p.D-r.D) > 1e-6 {
+			t.Errorf("expected '%v' but got '%v'", r, p)
+		}
+	}
+}
+
+func TestFrustumCornersIdentity(t *testing.T) {
+	c := FrustumCorners(IdentityMat())
+	r := [8]Vec3{
+		{-1, -1, -1}, {1, -1, -1}, {-1, 1, -1}, {1, 1, -1},
+		{-1, -1, 1}, {1, -1, 1}, {-1, 1, 1}, {1, 1, 1},
+	}
+	if c != r {
+		t.Errorf("expected '%v' but got '%v'", r, c)
+	}
+}
+
+func TestFrustumCorners(t *testing.T) {
+	m, _ := PerspectiveMat(math.Pi/2, 2, 1, 10).Inverse()
+	c := FrustumCorners(m)
+	near := Vec3{-2, -1, -1}
+	far := Vec3{20, 10, -10}
+	if !c[0].ApproxEq(&near, eps) {
+		t.Errorf("expected '%v' but got '%v'", near, c[0])
+	}
+	if !c[7].ApproxEq(&far, 1e-6) {
+		t.Errorf("expected '%v' but got '%v'", far, c[7])
+	}
+}
@@ -1,6 +1,8 @@
 package geom
 
 import (
+	"errors"
+	tmath "github.com/amsibamsi/three/math"
 	"math"
 	"math/rand"
 )
@@ -9,13 +11,100 @@ import (
 // x and y in this order.
 type Vec2 [2]int
 
+// Add adds another vector.
+func (v *Vec2) Add(w *Vec2) {
+	v[0] += w[0]
+	v[1] += w[1]
+}
+
+// Sub subtracts another vector.
+func (v *Vec2) Sub(w *Vec2) {
+	v[0] -= w[0]
+	v[1] -= w[1]
+}
+
+// Scale scales the vector.
+func (v *Vec2) Scale(s int) {
+	v[0] *= s
+	v[1] *= s
+}
+
+// Dot returns the dot product with another vector.
+func (v *Vec2) Dot(w *Vec2) int {
+	return v[0]*w[0] + v[1]*w[1]
+}
+
+// Manhattan returns the Manhattan distance to another vector, the sum of the
+// absolute differences of the components.
+func (v Vec2) Manhattan(w Vec2) int {
+	return tmath.Absi(v[0]-w[0]) + tmath.Absi(v[1]-w[1])
+}
+
+// Vec2f is a vector in 2D space with floating point cartesian coordinates.
+// Holds 2 components: x and y in this order.
+type Vec2f [2]float64
+
+// Add adds another vector.
+func (v *Vec2f) Add(w *Vec2f) {
+	v[0] += w[0]
+	v[1] += w[1]
+}
+
+// Sub subtracts another vector.
+func (v *Vec2f) Sub(w *Vec2f) {
+	v[0] -= w[0]
+	v[1] -= w[1]
+}
+
+// Scale scales the vector.
+func (v *Vec2f) Scale(s float64) {
+	v[0] *= s
+	v[1] *= s
+}
+
+// Dot returns the dot product with another vector.
+func (v *Vec2f) Dot(w *Vec2f) float64 {
+	return v[0]*w[0] + v[1]*w[1]
+}
+
+// Len returns the Euclidean length of the vector.
+func (v *Vec2f) Len() float64 {
+	return math.Sqrt(v.Dot(v))
+}
+
+// Norm normalizes a vector to length 1 keeping its direction.
+func (v *Vec2f) Norm() {
+	abs := v.Len()
+	if abs != 0 {
+		v[0] /= abs
+		v[1] /= abs
+	}
+}
+
 // Vec3 is a vector in 3D space with cartesian coordinates. Holds 3 components:
 // x, y and z in this order.
 type Vec3 [3]float64
 
+// Clone returns a new copy of the vector.
+func (v *Vec3) Clone() *Vec3 {
+	c := *v
+	return &c
+}
+
+// LenSq returns the squared length of the vector. It avoids the square root
+// when only comparing lengths.
+func (v *Vec3) LenSq() float64 {
+	return v[0]*v[0] + v[1]*v[1] + v[2]*v[2]
+}
+
+// Len returns the Euclidean length of the vector.
+func (v *Vec3) Len() float64 {
+	return math.Sqrt(v.LenSq())
+}
+
 // Norm normalizes a vector to length 1 keeping its direction.
 func (v *Vec3) Norm() {
-	abs := math.Sqrt(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])
+	abs := v.Len()
 	if abs != 0 {
 		v[0] /= abs
 		v[1] /= abs
@@ -23,6 +112,19 @@ func (v *Vec3) Norm() {
 	}
 }
 
+// Normalized returns a copy of the vector normalized to length 1. The returned
+// bool is false if the vector has length 0, in which case the copy is returned
+// unchanged.
+func (v *Vec3) Normalized() (Vec3, bool) {
+	n := *v
+	abs := n.Len()
+	if abs == 0 {
+		return n, false
+	}
+	n.Scale(1 / abs)
+	return n, true
+}
+
 // Neg negates the vector's components.
 func (v *Vec3) Neg() {
 	v[0] = -v[0]
@@ -51,6 +153,115 @@ func (v *Vec3) Scale(s float64) {
 	v[2] *= s
 }
 
+// MulComp multiplies the vector component-wise with another vector.
+func (v *Vec3) MulComp(w *Vec3) {
+	v[0] *= w[0]
+	v[1] *= w[1]
+	v[2] *= w[2]
+}
+
+// DivComp divides the vector component-wise by another vector. Components
+// that would be divided by 0 are set to 0 instead of becoming infinite or NaN.
+func (v *Vec3) DivComp(w *Vec3) {
+	for i := range v {
+		if w[i] == 0 {
+			v[i] = 0
+		} else {
+			v[i] /= w[i]
+		}
+	}
+}
+
+// ApproxEq returns true if all components of both vectors differ by at most
+// eps.
+func (v *Vec3) ApproxEq(w *Vec3, eps float64) bool {
+	for i := range v {
+		if math.Abs(v[i]-w[i]) > eps {
+			return false
+		}
+	}
+	return true
+}
+
+// IsFinite returns false if any component of the vector is NaN or infinite.
+func (v *Vec3) IsFinite() bool {
+	for _, c := range v {
+		if math.IsNaN(c) || math.IsInf(c, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// Quantize returns a copy of the vector with each component snapped to the
+// nearest multiple of grid. Vectors that differ by less than grid usually
+// snap to the same value and can then be used as stable map keys, though two
+// values very close to either side of a half-way point between multiples
+// still snap apart. A grid <= 0 returns the vector unchanged.
+func (v *Vec3) Quantize(grid float64) Vec3 {
+	q := *v
+	if grid <= 0 {
+		return q
+	}
+	for i := range q {
+		q[i] = math.Floor(q[i]/grid+0.5) * grid
+	}
+	return q
+}
+
+// Abs returns a new vector with the absolute values of the components.
+func (v *Vec3) Abs() *Vec3 {
+	return &Vec3{math.Abs(v[0]), math.Abs(v[1]), math.Abs(v[2])}
+}
+
+// sign returns -1, 0 or 1 depending on the sign of f.
+func sign(f float64) float64 {
+	switch {
+	case f < 0:
+		return -1
+	case f > 0:
+		return 1
+	}
+	return 0
+}
+
+// Sign returns a new vector with the signs of the components: -1 for
+// negative, 1 for positive and 0 for zero components.
+func (v *Vec3) Sign() *Vec3 {
+	return &Vec3{sign(v[0]), sign(v[1]), sign(v[2])}
+}
+
+// Floor returns a new vector with each component rounded down.
+func (v *Vec3) Floor() *Vec3 {
+	return &Vec3{math.Floor(v[0]), math.Floor(v[1]), math.Floor(v[2])}
+}
+
+// Ceil returns a new vector with each component rounded up.
+func (v *Vec3) Ceil() *Vec3 {
+	return &Vec3{math.Ceil(v[0]), math.Ceil(v[1]), math.Ceil(v[2])}
+}
+
+// Round returns a new vector with each component rounded to the nearest
+// integer. Like the Round function of the math package of this project,
+// halves are rounded up.
+func (v *Vec3) Round() *Vec3 {
+	return &Vec3{
+		math.Floor(v[0] + 0.5),
+		math.Floor(v[1] + 0.5),
+		math.Floor(v[2] + 0.5),
+	}
+}
+
+// Added returns a new vector that is the sum of the two vectors.
+func Added(v, w *Vec3) *Vec3 {
+	return &Vec3{v[0] + w[0], v[1] + w[1], v[2] + w[2]}
+}
+
+// Subbed returns a new vector that is the difference of the two vectors (v-w).
+func Subbed(v, w *Vec3) *Vec3 {
+	return &Vec3{v[0] - w[0], v[1] - w[1], v[2] - w[2]}
+}
+
 // Cross returns a new vector that is the cross product of the two vectors.
 func Cross(v, w *Vec3) *Vec3 {
 	return &Vec3{
@@ -60,17 +271,363 @@ func Cross(v, w *Vec3) *Vec3 {
 	}
 }
 
+// AddedInto stores the sum of the two vectors in dst, like Added but without
+// allocating a new vector. dst may be one of the operands.
+func AddedInto(dst, v, w *Vec3) {
+	*dst = Vec3{v[0] + w[0], v[1] + w[1], v[2] + w[2]}
+}
+
+// SubbedInto stores the difference of the two vectors (v-w) in dst, like
+// Subbed but without allocating a new vector. dst may be one of the operands.
+func SubbedInto(dst, v, w *Vec3) {
+	*dst = Vec3{v[0] - w[0], v[1] - w[1], v[2] - w[2]}
+}
+
+// CrossInto stores the cross product of the two vectors in dst, like Cross but
+// without allocating a new vector. dst may be one of the operands.
+func CrossInto(dst, v, w *Vec3) {
+	*dst = Vec3{
+		v[1]*w[2] - v[2]*w[1],
+		v[2]*w[0] - v[0]*w[2],
+		v[0]*w[1] - v[1]*w[0],
+	}
+}
+
+// Dot returns the dot product of the two vectors.
+func Dot(v, w *Vec3) float64 {
+	return v[0]*w[0] + v[1]*w[1] + v[2]*w[2]
+}
+
+// DistSq returns the squared distance between the two points. It avoids the
+// square root when only comparing distances.
+func DistSq(v, w *Vec3) float64 {
+	return Subbed(v, w).LenSq()
+}
+
+// Dist returns the distance between the two points.
+func Dist(v, w *Vec3) float64 {
+	return math.Sqrt(DistSq(v, w))
+}
+
+// Lerp returns a new vector that linearly interpolates between a and b: a for
+// t = 0 and b for t = 1. The parameter t is not clamped, values outside [0,1]
+// extrapolate beyond a or b.
+func Lerp(a, b *Vec3, t float64) *Vec3 {
+	return &Vec3{
+		a[0] + (b[0]-a[0])*t,
+		a[1] + (b[1]-a[1])*t,
+		a[2] + (b[2]-a[2])*t,
+	}
+}
+
+// LerpInto stores the linear interpolation between a and b in dst, like Lerp
+// but without allocating a new vector. dst may be one of the operands.
+func LerpInto(dst, a, b *Vec3, t float64) {
+	*dst = Vec3{
+		a[0] + (b[0]-a[0])*t,
+		a[1] + (b[1]-a[1])*t,
+		a[2] + (b[2]-a[2])*t,
+	}
+}
+
+// LerpClamped works like Lerp, but first clamps t to [0,1] so the result
+// always lies between a and b.
+func LerpClamped(a, b *Vec3, t float64) *Vec3 {
+	return Lerp(a, b, math.Max(0, math.Min(1, t)))
+}
+
+// Reflect returns a new vector that is the direction d reflected off a
+// surface with normal n, computed as d - 2*(d·n)*n. The normal n must be of
+// length 1, otherwise the result is scaled incorrectly.
+func Reflect(d, n *Vec3) *Vec3 {
+	r := *n
+	r.Scale(-2 * Dot(d, n))
+	r.Add(d)
+	return &r
+}
+
+// Project returns a new vector that is the projection of v onto the vector
+// onto. Projecting onto a vector of length 0 results in the zero vector.
+func Project(v, onto *Vec3) *Vec3 {
+	l := onto.LenSq()
+	if l == 0 {
+		return &Vec3{0, 0, 0}
+	}
+	p := *onto
+	p.Scale(Dot(v, onto) / l)
+	return &p
+}
+
+// ClosestPointOnSegment returns a new point on the line segment from a to b
+// that is nearest to p. Points beyond the ends of the segment result in the
+// nearest end point. If a and b are equal a is returned.
+func ClosestPointOnSegment(p, a, b *Vec3) *Vec3 {
+	d := Subbed(b, a)
+	l := d.LenSq()
+	if l == 0 {
+		c := *a
+		return &c
+	}
+	t := Dot(Subbed(p, a), d) / l
+	return LerpClamped(a, b, t)
+}
+
+// Angle returns the angle between the two vectors in radians, in the range
+// [0,π]. The cosine is clamped to [-1,1] before taking the arc cosine, so
+// rounding errors for (nearly) parallel vectors do not result in NaN. If one
+// of the vectors has length 0 the angle is 0.
+func Angle(v, w *Vec3) float64 {
+	l := v.Len() * w.Len()
+	if l == 0 {
+		return 0
+	}
+	c := Dot(v, w) / l
+	return math.Acos(math.Max(-1, math.Min(1, c)))
+}
+
+// RotateTowards returns a new unit vector that is the direction from rotated
+// towards the direction to by at most maxRad radians. If the angle between
+// them is at most maxRad, to normalized is returned. A negative maxRad is
+// treated as 0, so the result never turns away from to. If from and to point
+// in opposite directions there is no unique rotation, in that case from is
+// rotated around an arbitrary axis perpendicular to it.
+func RotateTowards(from, to *Vec3, maxRad float64) *Vec3 {
+	maxRad = math.Max(0, maxRad)
+	a := Angle(from, to)
+	if a <= maxRad {
+		t := *to
+		t.Norm()
+		return &t
+	}
+	axis := Cross(from, to)
+	if axis.LenSq() == 0 {
+		axis = Cross(leastAligned(from), from)
+	}
+	r := RotAxisMat(axis, maxRad).TransfDir(from)
+	r.Norm()
+	return r
+}
+
+// SlerpVec returns a new unit vector that interpolates between the directions
+// a and b along the great circle, with constant angular speed: a for t = 0 and
+// b for t = 1. Both vectors are normalized first and must not have length 0.
+// If they are nearly parallel the normalized linear interpolation is used
+// instead, avoiding the division by a tiny sine. If they point in opposite
+// directions the great circle is not unique, in that case the interpolation
+// goes through an arbitrary direction perpendicular to a.
+func SlerpVec(a, b *Vec3, t float64) *Vec3 {
+	u := *a
+	u.Norm()
+	v := *b
+	v.Norm()
+	d := math.Max(-1, math.Min(1, Dot(&u, &v)))
+	if d > 1-1e-6 {
+		r := Lerp(&u, &v, t)
+		r.Norm()
+		return r
+	}
+	if d < -1+1e-6 {
+		p := Cross(leastAligned(&u), &u)
+		p.Norm()
+		o := t * math.Pi
+		u.Scale(math.Cos(o))
+		p.Scale(math.Sin(o))
+		u.Add(p)
+		return &u
+	}
+	o := math.Acos(d)
+	s := math.Sin(o)
+	u.Scale(math.Sin((1-t)*o) / s)
+	v.Scale(math.Sin(t*o) / s)
+	u.Add(&v)
+	return &u
+}
+
+// SphereMidpoint returns a new unit vector halfway between the unit vectors a
+// and b on the great circle through both. For antipodal vectors every point on
+// the great circle perpendicular to them is a midpoint, in that case an
+// arbitrary one of them is returned.
+func SphereMidpoint(a, b *Vec3) *Vec3 {
+	m := Added(a, b)
+	if m.LenSq() < 1e-24 {
+		m = Cross(leastAligned(a), a)
+	}
+	m.Norm()
+	return m
+}
+
+// AngularDist returns the central angle between the unit vectors a and b in
+// radians, in the range [0,π], which is the distance along the great circle on
+// the unit sphere. It is computed from both sine and cosine and so stays
+// accurate for very close and nearly antipodal vectors.
+func AngularDist(a, b *Vec3) float64 {
+	return math.Atan2(Cross(a, b).Len(), Dot(a, b))
+}
+
+// Min returns a new vector with the component-wise minimum of the two vectors.
+func Min(a, b *Vec3) *Vec3 {
+	return &Vec3{
+		math.Min(a[0], b[0]),
+		math.Min(a[1], b[1]),
+		math.Min(a[2], b[2]),
+	}
+}
+
+// Max returns a new vector with the component-wise maximum of the two vectors.
+func Max(a, b *Vec3) *Vec3 {
+	return &Vec3{
+		math.Max(a[0], b[0]),
+		math.Max(a[1], b[1]),
+		math.Max(a[2], b[2]),
+	}
+}
+
+// Clamp clamps each component of the vector to the range given by the
+// corresponding components of min and max.
+func (v *Vec3) Clamp(min, max *Vec3) {
+	for i := range v {
+		v[i] = math.Max(min[i], math.Min(max[i], v[i]))
+	}
+}
+
+// Swizzle returns a new vector with the components of v at the indices i, j
+// and k, like v.xzy in shader code: Swizzle(0, 2, 1) swaps y and z. Indices can
+// repeat. It panics if an index is not in [0,2].
+func (v *Vec3) Swizzle(i, j, k int) *Vec3 {
+	if i < 0 || i > 2 || j < 0 || j > 2 || k < 0 || k > 2 {
+		panic("geom: vector index out of range")
+	}
+	return &Vec3{v[i], v[j], v[k]}
+}
+
+// ReflectPoint returns a new point that is the point reflected through center,
+// i.e. 2*center - v. Unlike Reflect this mirrors through a point, not off a
+// surface.
+func (v *Vec3) ReflectPoint(center *Vec3) *Vec3 {
+	return &Vec3{
+		2*center[0] - v[0],
+		2*center[1] - v[1],
+		2*center[2] - v[2],
+	}
+}
+
+// WeightedMean returns a new point that is the weighted average of the given
+// points, e.g. a center of mass. The weights do not need to sum up to 1, the
+// result is divided by their sum. It returns an error if the number of weights
+// does not match or the weights sum up to zero.
+func WeightedMean(points []Vec3, weights []float64) (*Vec3, error) {
+	if len(points) != len(weights) {
+		return nil, errors.New("Number of points and weights must match")
+	}
+	m := Vec3{0, 0, 0}
+	s := 0.0
+	for i := range points {
+		p := points[i]
+		p.Scale(weights[i])
+		m.Add(&p)
+		s += weights[i]
+	}
+	if s == 0 {
+		return nil, errors.New("Sum of weights is zero")
+	}
+	m.Scale(1 / s)
+	return &m, nil
+}
+
+// RandVec3Seed returns a new vector with random components in [0,1) from a
+// deterministic source with the given seed. The same seed always yields the
+// same vector.
+func RandVec3Seed(seed int64) *Vec3 {
+	r := rand.New(rand.NewSource(seed))
+	return &Vec3{r.Float64(), r.Float64(), r.Float64()}
+}
+
+// RandUnitVec3 returns a new vector of length 1 pointing in a random
+// direction, uniformly distributed on the unit sphere. It uses Marsaglia's
+// method: pick a random point (u,v) inside the unit disk by rejection and
+// map it onto the sphere, which unlike normalizing a random point in a cube
+// does not favor any direction.
+func RandUnitVec3(r *rand.Rand) *Vec3 {
+	for {
+		u := 2*r.Float64() - 1
+		v := 2*r.Float64() - 1
+		s := u*u + v*v
+		if s >= 1 {
+			continue
+		}
+		f := 2 * math.Sqrt(1-s)
+		return &Vec3{u * f, v * f, 1 - 2*s}
+	}
+}
+
+// ToSpherical returns the spherical coordinates of the vector: the length r,
+// the polar angle theta in [0,π] measured from the positive y axis and the
+// azimuth phi in [-π,π] measured in the xz plane from the positive z axis
+// towards the positive x axis. On the y axis phi is 0, for the zero vector
+// all angles are 0.
+func (v *Vec3) ToSpherical() (r, theta, phi float64) {
+	r = v.Len()
+	if r == 0 {
+		return 0, 0, 0
+	}
+	theta = math.Acos(math.Max(-1, math.Min(1, v[1]/r)))
+	phi = math.Atan2(v[0], v[2])
+	return r, theta, phi
+}
+
+// FromSpherical returns a new vector from spherical coordinates, using the
+// same convention as ToSpherical.
+func FromSpherical(r, theta, phi float64) *Vec3 {
+	st, ct := math.Sincos(theta)
+	sp, cp := math.Sincos(phi)
+	return &Vec3{r * st * sp, r * ct, r * st * cp}
+}
+
 // Vec4 is a vector in 3D space with homogeneous coordinates. Holds 4
 // components: x, y, z and w in this order.
 type Vec4 [4]float64
 
 // Norm normalizes a homogeneous vector by dividing x, y and z by w so that w
-// will be 1.
-func (v *Vec4) Norm() {
+// will be 1. Returns false and leaves the vector untouched if w is 0, e.g. for
+// a direction or a point on the camera plane after projection.
+func (v *Vec4) Norm() bool {
+	if v[3] == 0 {
+		return false
+	}
 	v[0] /= v[3]
 	v[1] /= v[3]
 	v[2] /= v[3]
 	v[3] = 1.0
+	return true
+}
+
+// Add adds another vector, including the w component.
+func (v *Vec4) Add(w *Vec4) {
+	v[0] += w[0]
+	v[1] += w[1]
+	v[2] += w[2]
+	v[3] += w[3]
+}
+
+// Sub subtracts another vector, including the w component.
+func (v *Vec4) Sub(w *Vec4) {
+	v[0] -= w[0]
+	v[1] -= w[1]
+	v[2] -= w[2]
+	v[3] -= w[3]
+}
+
+// Scale scales all components of the vector, including w.
+func (v *Vec4) Scale(s float64) {
+	v[0] *= s
+	v[1] *= s
+	v[2] *= s
+	v[3] *= s
+}
+
+// Dot returns the dot product with another vector over all 4 components.
+func (v *Vec4) Dot(w *Vec4) float64 {
+	return v[0]*w[0] + v[1]*w[1] + v[2]*w[2] + v[3]*w[3]
 }
 
 // NewVec4 returns a new vector with homogeneous coordinates corresponding to
@@ -79,6 +636,33 @@ func NewVec4(x, y, z float64) *Vec4 {
 	return &Vec4{x, y, z, 1}
 }
 
+// ToVec4 returns a new vector with homogeneous coordinates corresponding to
+// the vector (w will be 1).
+func (v *Vec3) ToVec4() *Vec4 {
+	return NewVec4(v[0], v[1], v[2])
+}
+
+// ToVec3 returns a new vector with the cartesian coordinates corresponding to
+// the homogeneous vector by dividing x, y and z by w. If w is 0 the vector is
+// a direction rather than a point and x, y and z are returned as they are.
+func (v *Vec4) ToVec3() *Vec3 {
+	if v[3] == 0 {
+		return &Vec3{v[0], v[1], v[2]}
+	}
+	return &Vec3{v[0] / v[3], v[1] / v[3], v[2] / v[3]}
+}
+
+// Perspective returns a new vector in normalized device coordinates from the
+// vector in clip coordinates, as produced by PerspectiveMat or OrthoMat, by
+// dividing x, y and z by w. A point with w = 0 lies on the plane through the
+// eye and has no device coordinates, in that case nil is returned.
+func (v *Vec4) Perspective() *Vec3 {
+	if v[3] == 0 {
+		return nil
+	}
+	return v.ToVec3()
+}
+
 // Mat4 is a matrix with homogeneous coordinates used to transform homogeneous
 // vectors.  Holds 16 components, the 4 first elements make up the first row
 // from left to right, and so on.
@@ -94,6 +678,79 @@ func ZeroMat() *Mat4 {
 	}
 }
 
+// IdentityMat returns a new identity matrix.
+func IdentityMat() *Mat4 {
+	return &Mat4{
+		1, 0, 0, 0,
+		0, 1, 0, 0,
+		0, 0, 1, 0,
+		0, 0, 0, 1,
+	}
+}
+
+// Clone returns a new copy of the matrix.
+func (m *Mat4) Clone() *Mat4 {
+	c := *m
+	return &c
+}
+
+// SetIdentity resets the matrix in place to the identity matrix.
+func (m *Mat4) SetIdentity() {
+	*m = Mat4{
+		1, 0, 0, 0,
+		0, 1, 0, 0,
+		0, 0, 1, 0,
+		0, 0, 0, 1,
+	}
+}
+
+// Outer returns a new matrix with the outer product v·wᵀ in its upper-left
+// 3x3 part, i.e. the component in row i and column j is v[i]*w[j]. All other
+// components, including the bottom right one, are 0.
+func Outer(v, w *Vec3) *Mat4 {
+	m := ZeroMat()
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			m[i*4+j] = v[i] * w[j]
+		}
+	}
+	return m
+}
+
+// SkewMat returns a new matrix with the skew-symmetric cross product matrix
+// [v]× in its upper-left 3x3 part, so that SkewMat(v).TransfDir(w) equals
+// Cross(v, w). Like with Outer all other components are 0.
+func SkewMat(v *Vec3) *Mat4 {
+	return &Mat4{
+		0, -v[2], v[1], 0,
+		v[2], 0, -v[0], 0,
+		-v[1], v[0], 0, 0,
+		0, 0, 0, 0,
+	}
+}
+
+// Mat4FromRows returns a new matrix with the given vectors as rows.
+func Mat4FromRows(r0, r1, r2, r3 *Vec4) *Mat4 {
+	return &Mat4{
+		r0[0], r0[1], r0[2], r0[3],
+		r1[0], r1[1], r1[2], r1[3],
+		r2[0], r2[1], r2[2], r2[3],
+		r3[0], r3[1], r3[2], r3[3],
+	}
+}
+
+// Mat4FromCols returns a new matrix with the given vectors as columns. E.g.
+// passing the x, y and z axis of a basis with w = 0 and its origin with w = 1
+// gives the matrix transforming from that basis to standard coordinates.
+func Mat4FromCols(c0, c1, c2, c3 *Vec4) *Mat4 {
+	return &Mat4{
+		c0[0], c1[0], c2[0], c3[0],
+		c0[1], c1[1], c2[1], c3[1],
+		c0[2], c1[2], c2[2], c3[2],
+		c0[3], c1[3], c2[3], c3[3],
+	}
+}
+
 // RandMat returns a new matrix random values.
 func RandMat(r *rand.Rand) *Mat4 {
 	m := Mat4{}
@@ -103,17 +760,452 @@ func RandMat(r *rand.Rand) *Mat4 {
 	return &m
 }
 
-// Mul multiplies the matrix with another one, modifying the former one.
+// RandMatSeed returns a new matrix with random values in [0,1) from a
+// deterministic source with the given seed. The same seed always yields the
+// same matrix.
+func RandMatSeed(seed int64) *Mat4 {
+	return RandMat(rand.New(rand.NewSource(seed)))
+}
+
+// TranslationMat returns a new matrix that translates vectors by x, y and z.
+func TranslationMat(x, y, z float64) *Mat4 {
+	return &Mat4{
+		1, 0, 0, x,
+		0, 1, 0, y,
+		0, 0, 1, z,
+		0, 0, 0, 1,
+	}
+}
+
+// ScaleMat returns a new matrix that scales vectors by sx, sy and sz along the
+// x, y and z axis.
+func ScaleMat(sx, sy, sz float64) *Mat4 {
+	return &Mat4{
+		sx, 0, 0, 0,
+		0, sy, 0, 0,
+		0, 0, sz, 0,
+		0, 0, 0, 1,
+	}
+}
+
+// ShearMat returns a new matrix that shears vectors. Each parameter is named
+// after the axis that is shifted followed by the axis it is shifted in
+// proportion to, e.g. xy shifts x by xy times y:
+//
+//	x' = x + xy*y + xz*z
+//	y' = y + yx*x + yz*z
+//	z' = z + zx*x + zy*y
+func ShearMat(xy, xz, yx, yz, zx, zy float64) *Mat4 {
+	return &Mat4{
+		1, xy, xz, 0,
+		yx, 1, yz, 0,
+		zx, zy, 1, 0,
+		0, 0, 0, 1,
+	}
+}
+
+// RotXMat returns a new matrix that rotates vectors by rad radians around the
+// x axis. The coordinate system is right-handed: a positive angle rotates
+// counter-clockwise when looking from the positive x axis towards the origin,
+// turning y towards z.
+func RotXMat(rad float64) *Mat4 {
+	s, c := math.Sin(rad), math.Cos(rad)
+	return &Mat4{
+		1, 0, 0, 0,
+		0, c, -s, 0,
+		0, s, c, 0,
+		0, 0, 0, 1,
+	}
+}
+
+// RotYMat returns a new matrix that rotates vectors by rad radians around the
+// y axis. The coordinate system is right-handed: a positive angle rotates
+// counter-clockwise when looking from the positive y axis towards the origin,
+// turning z towards x.
+func RotYMat(rad float64) *Mat4 {
+	s, c := math.Sin(rad), math.Cos(rad)
+	return &Mat4{
+		c, 0, s, 0,
+		0, 1, 0, 0,
+		-s, 0, c, 0,
+		0, 0, 0, 1,
+	}
+}
+
+// RotZMat returns a new matrix that rotates vectors by rad radians around the
+// z axis. The coordinate system is right-handed: a positive angle rotates
+// counter-clockwise when looking from the positive z axis towards the origin,
+// turning x towards y.
+func RotZMat(rad float64) *Mat4 {
+	s, c := math.Sin(rad), math.Cos(rad)
+	return &Mat4{
+		c, -s, 0, 0,
+		s, c, 0, 0,
+		0, 0, 1, 0,
+		0, 0, 0, 1,
+	}
+}
+
+// RotAxisMat returns a new matrix that rotates vectors by rad radians around
+// the given axis, following the same right-handed convention as RotXMat,
+// RotYMat and RotZMat. The axis is normalized first, it does not need to be of
+// length 1. An axis of length 0 results in the identity matrix.
+//
+// The matrix is built with Rodrigues' rotation formula:
+//
+//	R = cos(rad)*I + (1-cos(rad))*a*aᵀ + sin(rad)*[a]×
+//
+// where a is the normalized axis and [a]× the cross product matrix of a.
+func RotAxisMat(axis *Vec3, rad float64) *Mat4 {
+	a, ok := axis.Normalized()
+	if !ok {
+		return IdentityMat()
+	}
+	s, c := math.Sin(rad), math.Cos(rad)
+	t := 1 - c
+	x, y, z := a[0], a[1], a[2]
+	return &Mat4{
+		c + t*x*x, t*x*y - s*z, t*x*z + s*y, 0,
+		t*x*y + s*z, c + t*y*y, t*y*z - s*x, 0,
+		t*x*z - s*y, t*y*z + s*x, c + t*z*z, 0,
+		0, 0, 0, 1,
+	}
+}
+
+// EulerToMat4 returns a new rotation matrix from Euler angles in radians. The
+// rotations are composed in YXZ order:
+//
+//	RotYMat(yaw) · RotXMat(pitch) · RotZMat(roll)
+//
+// so a vector is first rolled around z, then pitched around x and last yawed
+// around y. All rotations are right-handed as described for RotXMat, RotYMat
+// and RotZMat, which for a viewer looking along the negative z axis with y up
+// means positive pitch looks up, positive yaw turns left and positive roll
+// tilts counter-clockwise.
+func EulerToMat4(pitch, yaw, roll float64) *Mat4 {
+	m := RotYMat(yaw)
+	m.Mul(RotXMat(pitch))
+	m.Mul(RotZMat(roll))
+	return m
+}
+
+// ToEuler returns the Euler angles in radians of the rotation part of the
+// matrix, in the same YXZ order as EulerToMat4. Pitch is in [-π/2,π/2], yaw
+// and roll in [-π,π]. The matrix must be a pure rotation.
+//
+// At pitch ±π/2 (gimbal lock) yaw and roll rotate around the same axis and
+// cannot be told apart. In that case roll is set to 0 and the whole rotation
+// around that axis is returned as yaw.
+func (m *Mat4) ToEuler() (pitch, yaw, roll float64) {
+	sp := math.Max(-1, math.Min(1, -m[6]))
+	pitch = math.Asin(sp)
+	if math.Abs(sp) > 1-1e-12 {
+		return pitch, math.Atan2(-m[8], m[0]), 0
+	}
+	return pitch, math.Atan2(m[2], m[10]), math.Atan2(m[4], m[5])
+}
+
+// PerspectiveMat returns a new perspective projection matrix with the vertical
+// field of view fovyRad in radians, the aspect ratio of width to height, and
+// the distances to the near and far plane. It follows the OpenGL convention:
+// the camera looks along the negative z axis and after dividing by w the
+// visible volume maps to normalized device coordinates in [-1,1] on all axes,
+// with the near plane at z = -1 and the far plane at z = 1.
+//
+// If aspect is 0 or near equals far no projection is possible and nil is
+// returned.
+func PerspectiveMat(fovyRad, aspect, near, far float64) *Mat4 {
+	if aspect == 0 || near == far {
+		return nil
+	}
+	f := 1 / math.Tan(fovyRad/2)
+	d := near - far
+	return &Mat4{
+		f / aspect, 0, 0, 0,
+		0, f, 0, 0,
+		0, 0, (far + near) / d, 2 * far * near / d,
+		0, 0, -1, 0,
+	}
+}
+
+// OrthoMat returns a new orthographic projection matrix that maps the box
+// given by the left, right, bottom and top planes and the distances to the
+// near and far plane to normalized device coordinates in [-1,1]. Like
+// PerspectiveMat it follows the OpenGL convention of looking along the
+// negative z axis, with the near plane at z = -1 and the far plane at z = 1.
+// Left always maps to x = -1 and bottom always to y = -1, so passing top <
+// bottom simply flips the y axis, which is handy for screen coordinates with y
+// pointing down.
+//
+// If the box is empty along any axis no projection is possible and nil is
+// returned.
+func OrthoMat(left, right, bottom, top, near, far float64) *Mat4 {
+	if left == right || bottom == top || near == far {
+		return nil
+	}
+	w := right - left
+	h := top - bottom
+	d := far - near
+	return &Mat4{
+		2 / w, 0, 0, -(right + left) / w,
+		0, 2 / h, 0, -(top + bottom) / h,
+		0, 0, -2 / d, -(far + near) / d,
+		0, 0, 0, 1,
+	}
+}
+
+// ViewportMat returns a new matrix that maps normalized device coordinates in
+// [-1,1] to the viewport with the corner (x,y) and the given width and height:
+// x = -1 maps to x and x = 1 to x+width. Without flipY, y = -1 maps to y and
+// y = 1 to y+height like in OpenGL window coordinates. With flipY the y axis
+// is flipped for screen coordinates where y points down: y = 1 maps to y and
+// y = -1 to y+height. The z coordinate is mapped to a depth in [0,1].
+func ViewportMat(x, y, width, height float64, flipY bool) *Mat4 {
+	hw := width / 2
+	hh := height / 2
+	sy := hh
+	if flipY {
+		sy = -hh
+	}
+	return &Mat4{
+		hw, 0, 0, x + hw,
+		0, sy, 0, y + hh,
+		0, 0, 0.5, 0.5,
+		0, 0, 0, 1,
+	}
+}
+
+// ZUpToYUp returns a new matrix that converts coordinates from a right-handed
+// system with z pointing up to a right-handed system with y pointing up: x
+// stays x, z becomes y and y becomes -z. It is a rotation of -90° around the x
+// axis, so a point in front (+y) in the Z-up system ends up at -z, which is in
+// front of an OpenGL camera.
+func ZUpToYUp() *Mat4 {
+	return &Mat4{
+		1, 0, 0, 0,
+		0, 0, 1, 0,
+		0, -1, 0, 0,
+		0, 0, 0, 1,
+	}
+}
+
+// YUpToZUp returns a new matrix that is the inverse of ZUpToYUp: x stays x, y
+// becomes z and z becomes -y.
+func YUpToZUp() *Mat4 {
+	return &Mat4{
+		1, 0, 0, 0,
+		0, 0, -1, 0,
+		0, 1, 0, 0,
+		0, 0, 0, 1,
+	}
+}
+
+// LookAt returns a new view matrix that transforms world coordinates into the
+// coordinates of a viewer at eye looking towards center. Like in OpenGL the
+// viewer looks along its negative z axis, y points along up and x to the
+// right.
+//
+// If the looking direction is parallel to up, no right vector can be derived
+// from their cross product. In that case up is replaced by the world axis
+// least aligned with the looking direction, so the result is always a valid
+// view matrix, only the roll around the looking direction is arbitrary.
+func LookAt(eye, center, up *Vec3) *Mat4 {
+	z := Subbed(eye, center)
+	z.Norm()
+	x := Cross(up, z)
+	if x.LenSq() == 0 {
+		x = Cross(leastAligned(z), z)
+	}
+	x.Norm()
+	y := Cross(z, x)
+	return &Mat4{
+		x[0], x[1], x[2], -Dot(x, eye),
+		y[0], y[1], y[2], -Dot(y, eye),
+		z[0], z[1], z[2], -Dot(z, eye),
+		0, 0, 0, 1,
+	}
+}
+
+// Billboard returns a new model matrix that places an object at pos and
+// rotates it to face the camera at camPos, for sprites that should always be
+// seen in full. The local z axis of the object points from pos towards camPos,
+// y is aligned with camUp as far as possible and x points to the right as seen
+// from the camera. If the camera is at pos the object is not rotated, if the
+// direction to the camera is parallel to camUp the roll is arbitrary like in
+// LookAt.
+func Billboard(pos, camPos, camUp *Vec3) *Mat4 {
+	z := Subbed(camPos, pos)
+	if z.LenSq() == 0 {
+		z = &Vec3{0, 0, 1}
+	}
+	z.Norm()
+	x := Cross(camUp, z)
+	if x.LenSq() == 0 {
+		x = Cross(leastAligned(z), z)
+	}
+	x.Norm()
+	y := Cross(z, x)
+	return &Mat4{
+		x[0], y[0], z[0], pos[0],
+		x[1], y[1], z[1], pos[1],
+		x[2], y[2], z[2], pos[2],
+		0, 0, 0, 1,
+	}
+}
+
+// BillboardCylindrical works like Billboard, but keeps the local y axis of the
+// object fixed along up and only rotates around it to face the camera, for
+// objects like trees that should stay upright. The local z axis points
+// towards the projection of camPos onto the plane through pos perpendicular to
+// up. If the camera is directly above or below pos the rotation around up is
+// arbitrary.
+func BillboardCylindrical(pos, camPos, up *Vec3) *Mat4 {
+	y := *up
+	y.Norm()
+	x := Cross(&y, Subbed(camPos, pos))
+	if x.LenSq() == 0 {
+		x = Cross(&y, leastAligned(&y))
+	}
+	x.Norm()
+	z := Cross(x, &y)
+	return &Mat4{
+		x[0], y[0], z[0], pos[0],
+		x[1], y[1], z[1], pos[1],
+		x[2], y[2], z[2], pos[2],
+		0, 0, 0, 1,
+	}
+}
+
+// OrthoBasis returns two new unit vectors that are perpendicular to forward
+// and to each other, e.g. for a tangent space around a normal. Like the axes
+// of a view from LookAt, right, up and the negative forward direction form a
+// right-handed basis. The roll around forward is arbitrary: right is derived
+// from the world axis least aligned with forward, so the result is stable for
+// any direction, including ones parallel to the y axis. The forward vector
+// does not need to be of length 1, but it must not be of length 0.
+func OrthoBasis(forward *Vec3) (right, up *Vec3) {
+	f := *forward
+	f.Norm()
+	right = Cross(&f, leastAligned(&f))
+	right.Norm()
+	up = Cross(right, &f)
+	return right, up
+}
+
+// leastAligned returns the world axis that is least parallel to v.
+func leastAligned(v *Vec3) *Vec3 {
+	x, y, z := math.Abs(v[0]), math.Abs(v[1]), math.Abs(v[2])
+	if x <= y && x <= z {
+		return &Vec3{1, 0, 0}
+	}
+	if y <= z {
+		return &Vec3{0, 1, 0}
+	}
+	return &Vec3{0, 0, 1}
+}
+
+// checkIndex panics if i is not a valid row or column index.
+func checkIndex(i int) {
+	if i < 0 || i > 3 {
+		panic("geom: matrix index out of range")
+	}
+}
+
+// Row returns the i-th row of the matrix, counting from 0. It panics if i is
+// not in [0,3].
+func (m *Mat4) Row(i int) Vec4 {
+	checkIndex(i)
+	return Vec4{m[i*4], m[i*4+1], m[i*4+2], m[i*4+3]}
+}
+
+// Col returns the j-th column of the matrix, counting from 0. It panics if j
+// is not in [0,3].
+func (m *Mat4) Col(j int) Vec4 {
+	checkIndex(j)
+	return Vec4{m[j], m[4+j], m[8+j], m[12+j]}
+}
+
+// At returns the component in row i and column j, counting from 0. It panics
+// if i or j is not in [0,3].
+func (m *Mat4) At(i, j int) float64 {
+	checkIndex(i)
+	checkIndex(j)
+	return m[i*4+j]
+}
+
+// SetAt sets the component in row i and column j, counting from 0. It panics
+// if i or j is not in [0,3].
+func (m *Mat4) SetAt(i, j int, f float64) {
+	checkIndex(i)
+	checkIndex(j)
+	m[i*4+j] = f
+}
+
+// AddMat adds another matrix component-wise.
+func (m *Mat4) AddMat(n *Mat4) {
+	for i := range m {
+		m[i] += n[i]
+	}
+}
+
+// ScaleBy multiplies all components of the matrix by s.
+func (m *Mat4) ScaleBy(s float64) {
+	for i := range m {
+		m[i] *= s
+	}
+}
+
+// Trace returns the sum of the diagonal components of the matrix.
+func (m *Mat4) Trace() float64 {
+	return m[0] + m[5] + m[10] + m[15]
+}
+
+// Mul multiplies the matrix with another one, modifying the former one. It
+// computes the matrix product m·n in standard notation, so transforming a
+// vector with the result applies n first and then m.
+//
+// The loops are unrolled and the result is built in a matrix on the stack,
+// avoiding an allocation and bounds checks. This also makes it safe to
+// multiply a matrix with itself.
 func (m *Mat4) Mul(n *Mat4) {
-	t := ZeroMat()
-	for i := 0; i < 4; i++ {
-		for j := 0; j < 4; j++ {
-			for k := 0; k < 4; k++ {
-				t[i*4+j] += m[i*4+k] * n[j+k*4]
-			}
+	var t Mat4
+	for i := 0; i < 16; i += 4 {
+		a0, a1, a2, a3 := m[i], m[i+1], m[i+2], m[i+3]
+		t[i] = a0*n[0] + a1*n[4] + a2*n[8] + a3*n[12]
+		t[i+1] = a0*n[1] + a1*n[5] + a2*n[9] + a3*n[13]
+		t[i+2] = a0*n[2] + a1*n[6] + a2*n[10] + a3*n[14]
+		t[i+3] = a0*n[3] + a1*n[7] + a2*n[11] + a3*n[15]
+	}
+	*m = t
+}
+
+// Mul returns a new matrix that is the product of the two matrices (a·b),
+// leaving both unchanged.
+func Mul(a, b *Mat4) *Mat4 {
+	p := *a
+	p.Mul(b)
+	return &p
+}
+
+// ApproxEq returns true if all components of both matrices differ by at most
+// eps.
+func (m *Mat4) ApproxEq(n *Mat4, eps float64) bool {
+	for i := range m {
+		if math.Abs(m[i]-n[i]) > eps {
+			return false
 		}
 	}
-	*m = *t
+	return true
+}
+
+// IsFinite returns false if any component of the matrix is NaN or infinite.
+func (m *Mat4) IsFinite() bool {
+	for _, c := range m {
+		if math.IsNaN(c) || math.IsInf(c, 0) {
+			return false
+		}
+	}
+	return true
 }
 
 // Transf returns a new transformed vector by multiplying the matrix with the
@@ -127,3 +1219,287 @@ func (m *Mat4) Transf(v *Vec4) *Vec4 {
 	}
 	return &p
 }
+
+// TransfDir returns a new transformed direction by multiplying the upper-left
+// 3x3 part of the matrix with the given vector. Unlike points, directions are
+// not affected by the translation of the matrix.
+func (m *Mat4) TransfDir(v *Vec3) *Vec3 {
+	return &Vec3{
+		m[0]*v[0] + m[1]*v[1] + m[2]*v[2],
+		m[4]*v[0] + m[5]*v[1] + m[6]*v[2],
+		m[8]*v[0] + m[9]*v[1] + m[10]*v[2],
+	}
+}
+
+// TransfAll transforms all vectors in the slice in place by multiplying the
+// matrix with each of them. It gives the same results as calling Transf for
+// every vector, but without allocating a new vector each time.
+func (m *Mat4) TransfAll(points []Vec4) {
+	for k := range points {
+		v := points[k]
+		p := &points[k]
+		for i := 0; i < 4; i++ {
+			p[i] = m[i*4]*v[0] + m[i*4+1]*v[1] + m[i*4+2]*v[2] + m[i*4+3]*v[3]
+		}
+	}
+}
+
+// Transpose transposes the matrix in place, swapping rows and columns.
+func (m *Mat4) Transpose() {
+	for i := 0; i < 4; i++ {
+		for j := i + 1; j < 4; j++ {
+			m[i*4+j], m[j*4+i] = m[j*4+i], m[i*4+j]
+		}
+	}
+}
+
+// Transposed returns a new matrix that is the transpose of the given one.
+func Transposed(m *Mat4) *Mat4 {
+	t := *m
+	t.Transpose()
+	return &t
+}
+
+// minors returns the determinants of all 2x2 sub-matrices formed by the first
+// two rows (s) and the last two rows (c) of the matrix. They are the building
+// blocks for the determinant and the inverse.
+func (m *Mat4) minors() (s, c [6]float64) {
+	s[0] = m[0]*m[5] - m[4]*m[1]
+	s[1] = m[0]*m[6] - m[4]*m[2]
+	s[2] = m[0]*m[7] - m[4]*m[3]
+	s[3] = m[1]*m[6] - m[5]*m[2]
+	s[4] = m[1]*m[7] - m[5]*m[3]
+	s[5] = m[2]*m[7] - m[6]*m[3]
+	c[0] = m[8]*m[13] - m[12]*m[9]
+	c[1] = m[8]*m[14] - m[12]*m[10]
+	c[2] = m[8]*m[15] - m[12]*m[11]
+	c[3] = m[9]*m[14] - m[13]*m[10]
+	c[4] = m[9]*m[15] - m[13]*m[11]
+	c[5] = m[10]*m[15] - m[14]*m[11]
+	return s, c
+}
+
+// Det returns the determinant of the matrix. A determinant of 0 means the
+// matrix is not invertible.
+func (m *Mat4) Det() float64 {
+	return detMinors(m.minors())
+}
+
+// detMinors returns the determinant of a matrix from its 2x2 minors as
+// returned by minors().
+func detMinors(s, c [6]float64) float64 {
+	return s[0]*c[5] - s[1]*c[4] + s[2]*c[3] + s[3]*c[2] - s[4]*c[1] + s[5]*c[0]
+}
+
+// detEps is the threshold below which a determinant is considered to be zero,
+// relative to the product of the lengths of the rows of the matrix. By
+// Hadamard's inequality the absolute value of the determinant is at most that
+// product, with equality for orthogonal rows, so the ratio does not depend on
+// the scale of the matrix and only gets small for (nearly) dependent rows.
+const detEps = 1e-12
+
+// Inverse returns a new matrix that is the inverse of the matrix. The returned
+// bool is false if the matrix is not invertible because its determinant is
+// (near) zero, in which case the returned matrix is nil. The determinant is
+// compared to detEps relative to the lengths of the rows, so well-conditioned
+// matrices like small uniform scales still invert. It is also false if the
+// inverse is too large to be represented.
+func (m *Mat4) Inverse() (*Mat4, bool) {
+	return m.InverseEps(0)
+}
+
+// InverseEps works like Inverse, but also treats the matrix as not invertible
+// if the absolute value of its determinant is at most eps. Unlike the relative
+// check of Inverse this depends on the scale of the matrix, since the
+// determinant of a matrix scaled by s is scaled by s⁴.
+func (m *Mat4) InverseEps(eps float64) (*Mat4, bool) {
+	s, c := m.minors()
+	det := detMinors(s, c)
+	h := 1.0
+	for k := 0; k < 4; k++ {
+		r := m.Row(k)
+		h *= math.Sqrt(r.Dot(&r))
+	}
+	if a := math.Abs(det); a <= eps || a <= detEps*h {
+		return nil, false
+	}
+	d := 1 / det
+	i := &Mat4{
+		(m[5]*c[5] - m[6]*c[4] + m[7]*c[3]) * d,
+		(-m[1]*c[5] + m[2]*c[4] - m[3]*c[3]) * d,
+		(m[13]*s[5] - m[14]*s[4] + m[15]*s[3]) * d,
+		(-m[9]*s[5] + m[10]*s[4] - m[11]*s[3]) * d,
+
+		(-m[4]*c[5] + m[6]*c[2] - m[7]*c[1]) * d,
+		(m[0]*c[5] - m[2]*c[2] + m[3]*c[1]) * d,
+		(-m[12]*s[5] + m[14]*s[2] - m[15]*s[1]) * d,
+		(m[8]*s[5] - m[10]*s[2] + m[11]*s[1]) * d,
+
+		(m[4]*c[4] - m[5]*c[2] + m[7]*c[0]) * d,
+		(-m[0]*c[4] + m[1]*c[2] - m[3]*c[0]) * d,
+		(m[12]*s[4] - m[13]*s[2] + m[15]*s[0]) * d,
+		(-m[8]*s[4] + m[9]*s[2] - m[11]*s[0]) * d,
+
+		(-m[4]*c[3] + m[5]*c[1] - m[6]*c[0]) * d,
+		(m[0]*c[3] - m[1]*c[1] + m[2]*c[0]) * d,
+		(-m[12]*s[3] + m[13]*s[1] - m[14]*s[0]) * d,
+		(m[8]*s[3] - m[9]*s[1] + m[10]*s[0]) * d,
+	}
+	if !i.IsFinite() {
+		return nil, false
+	}
+	return i, true
+}
+
+// Pow returns a new matrix that is the matrix raised to the integer power n,
+// computed by repeated squaring with O(log n) multiplications. For n = 0 the
+// identity is returned, for negative n the inverse is raised to -n. Returns
+// nil if n is negative and the matrix is not invertible.
+func (m *Mat4) Pow(n int) *Mat4 {
+	b := m.Clone()
+	if n < 0 {
+		i, ok := m.Inverse()
+		if !ok {
+			return nil
+		}
+		b = i
+		n = -n
+	}
+	r := IdentityMat()
+	for n > 0 {
+		if n&1 == 1 {
+			r.Mul(b)
+		}
+		b.Mul(b)
+		n >>= 1
+	}
+	return r
+}
+
+// Decompose splits an affine transformation matrix into its translation,
+// rotation and scale, such that
+//
+//	TranslationMat(t) · rotation · ScaleMat(s)
+//
+// equals the matrix. The scale along each axis is the length of the
+// corresponding column of the upper-left 3x3 part, the rotation is made up of
+// the columns divided by their scale. If the determinant is negative the
+// matrix mirrors, which is represented by negating the x scale, so the
+// rotation stays a proper rotation. A scale of 0 leaves the corresponding
+// rotation column 0. Shear cannot be represented, for a sheared matrix the
+// returned rotation is not orthonormal.
+func (m *Mat4) Decompose() (translation *Vec3, rotation *Mat4, scale *Vec3) {
+	translation = &Vec3{m[3], m[7], m[11]}
+	scale = &Vec3{}
+	rotation = IdentityMat()
+	for j := 0; j < 3; j++ {
+		c := Vec3{m[j], m[4+j], m[8+j]}
+		scale[j] = c.Len()
+	}
+	if m.Det() < 0 {
+		scale[0] = -scale[0]
+	}
+	for j := 0; j < 3; j++ {
+		for i := 0; i < 3; i++ {
+			if scale[j] != 0 {
+				rotation[i*4+j] = m[i*4+j] / scale[j]
+			} else {
+				rotation[i*4+j] = 0
+			}
+		}
+	}
+	return translation, rotation, scale
+}
+
+// NormalMatrix returns a new matrix that transforms surface normals
+// consistently with the matrix transforming the surface. It is the
+// inverse-transpose of the upper-left 3x3 part, embedded in a homogeneous
+// matrix without translation. For rotations it equals the rotation, for
+// non-uniform scales it scales inversely so normals stay perpendicular to the
+// surface. Transformed normals still need to be normalized. If the 3x3 part is
+// not invertible nil is returned.
+func (m *Mat4) NormalMatrix() *Mat4 {
+	n := *m
+	n[3], n[7], n[11] = 0, 0, 0
+	n[12], n[13], n[14], n[15] = 0, 0, 0, 1
+	i, ok := n.Inverse()
+	if !ok {
+		return nil
+	}
+	i.Transpose()
+	return i
+}
+
+// Orthonormalize makes the columns of the upper-left 3x3 part of the matrix
+// orthonormal using the Gram-Schmidt process, leaving the rest untouched. The
+// first column keeps its direction, the second is made perpendicular to the
+// first and the third to both. It is used to correct the drift of a rotation
+// matrix after many incremental updates.
+func (m *Mat4) Orthonormalize() {
+	x := &Vec3{m[0], m[4], m[8]}
+	y := &Vec3{m[1], m[5], m[9]}
+	z := &Vec3{m[2], m[6], m[10]}
+	x.Norm()
+	y.Sub(Project(y, x))
+	y.Norm()
+	z.Sub(Project(z, x))
+	z.Sub(Project(z, y))
+	z.Norm()
+	for i := 0; i < 3; i++ {
+		m[i*4] = x[i]
+		m[i*4+1] = y[i]
+		m[i*4+2] = z[i]
+	}
+}
+
+// Covariance returns a new matrix with the 3x3 covariance matrix of the points
+// in its upper-left part and the rest like the identity. Its eigenvectors are
+// the principal axes of the point set, e.g. to fit an oriented bounding box.
+// The covariance is divided by the number of points, not the number minus 1.
+// Returns nil if there are no points.
+func Covariance(points []Vec3) *Mat4 {
+	if len(points) == 0 {
+		return nil
+	}
+	c := Vec3{0, 0, 0}
+	for i := range points {
+		c.Add(&points[i])
+	}
+	c.Scale(1 / float64(len(points)))
+	m := ZeroMat()
+	for i := range points {
+		d := Subbed(&points[i], &c)
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				m[j*4+k] += d[j] * d[k]
+			}
+		}
+	}
+	m.ScaleBy(1 / float64(len(points)))
+	m[15] = 1
+	return m
+}
+
+// DominantEigenvector returns a new unit vector that is the eigenvector with
+// the largest absolute eigenvalue of the upper-left 3x3 part of the matrix,
+// found by power iteration. It is meant for symmetric matrices like the ones
+// from Covariance, where it is the principal axis. The sign of the result is
+// arbitrary. Returns nil if the iteration reaches the zero vector, e.g. for
+// the covariance of identical points.
+func DominantEigenvector(m *Mat4) *Vec3 {
+	v := &Vec3{1, 0.5, 0.25}
+	v.Norm()
+	for i := 0; i < 1000; i++ {
+		w := m.TransfDir(v)
+		if w.LenSq() == 0 {
+			return nil
+		}
+		w.Norm()
+		d := math.Abs(Dot(v, w))
+		v = w
+		if d > 1-1e-15 {
+			break
+		}
+	}
+	return v
+}
@@ -1,11 +1,142 @@
 package geom
 
 import (
+	"math"
 	"math/rand"
 	"reflect"
 	"testing"
 )
 
+// eps is the tolerance used when comparing floating point results.
+const eps = 1e-9
+
+// vec4ApproxEq returns true if all components of both vectors differ by at
+// most e.
+func vec4ApproxEq(v, w *Vec4, e float64) bool {
+	for i := range v {
+		if math.Abs(v[i]-w[i]) > e {
+			return false
+		}
+	}
+	return true
+}
+
+func TestAdd2(t *testing.T) {
+	v := Vec2{5, -2}
+	v.Add(&Vec2{1, 3})
+	r := Vec2{6, 1}
+	if v != r {
+		t.Errorf("expected '%v' but got '%v'", r, v)
+	}
+}
+
+func TestSub2(t *testing.T) {
+	v := Vec2{5, -2}
+	v.Sub(&Vec2{1, 3})
+	r := Vec2{4, -5}
+	if v != r {
+		t.Errorf("expected '%v' but got '%v'", r, v)
+	}
+}
+
+func TestScale2(t *testing.T) {
+	v := Vec2{5, -2}
+	v.Scale(-3)
+	r := Vec2{-15, 6}
+	if v != r {
+		t.Errorf("expected '%v' but got '%v'", r, v)
+	}
+}
+
+func TestDot2(t *testing.T) {
+	v := Vec2{5, -2}
+	d := v.Dot(&Vec2{1, 3})
+	if d != -1 {
+		t.Errorf("expected '%v' but got '%v'", -1, d)
+	}
+}
+
+var manhattantests = []struct {
+	v, w Vec2
+	dist int
+}{
+	{Vec2{0, 0}, Vec2{3, 4}, 7},
+	{Vec2{-2, 5}, Vec2{1, -1}, 9},
+	{Vec2{1, 1}, Vec2{1, 1}, 0},
+}
+
+func TestManhattan(t *testing.T) {
+	for _, test := range manhattantests {
+		d := test.v.Manhattan(test.w)
+		if d != test.dist {
+			t.Errorf("expected '%v' but got '%v'", test.dist, d)
+		}
+	}
+}
+
+func TestVec2f(t *testing.T) {
+	v := Vec2f{1, 2}
+	v.Add(&Vec2f{2, 2})
+	v.Scale(2)
+	v.Sub(&Vec2f{3, 4})
+	r := Vec2f{3, 4}
+	if v != r {
+		t.Errorf("expected '%v' but got '%v'", r, v)
+	}
+	if d := v.Dot(&Vec2f{-1, 1}); d != 1 {
+		t.Errorf("expected '%v' but got '%v'", 1, d)
+	}
+}
+
+func TestLen2f(t *testing.T) {
+	v := Vec2f{3, 4}
+	if l := v.Len(); l != 5 {
+		t.Errorf("expected '%v' but got '%v'", 5, l)
+	}
+}
+
+func TestNorm2f(t *testing.T) {
+	v := Vec2f{3, 4}
+	v.Norm()
+	r := Vec2f{0.6, 0.8}
+	if v != r {
+		t.Errorf("expected '%v' but got '%v'", r, v)
+	}
+	z := Vec2f{0, 0}
+	z.Norm()
+	if z != (Vec2f{0, 0}) {
+		t.Errorf("expected '%v' but got '%v'", Vec2f{0, 0}, z)
+	}
+}
+
+func TestVec3Clone(t *testing.T) {
+	v := &Vec3{1, 2, 3}
+	c := v.Clone()
+	if *c != *v {
+		t.Errorf("expected '%v' but got '%v'", *v, *c)
+	}
+	c[0] = 5
+	if v[0] != 1 {
+		t.Errorf("expected clone to not alias original but got '%v'", *v)
+	}
+}
+
+func TestLen(t *testing.T) {
+	v := Vec3{3, 4, 0}
+	l := v.Len()
+	if l != 5 {
+		t.Errorf("expected '%v' but got '%v'", 5, l)
+	}
+}
+
+func TestLenSq(t *testing.T) {
+	v := Vec3{3, 4, 0}
+	l := v.LenSq()
+	if l != 25 {
+		t.Errorf("expected '%v' but got '%v'", 25, l)
+	}
+}
+
 var norm3tests = []struct {
 	vec, norm Vec3
 }{
@@ -24,6 +155,32 @@ func TestNorm3(t *testing.T) {
 	}
 }
 
+func TestNormalizedZero(t *testing.T) {
+	v := Vec3{0, 0, 0}
+	n, ok := v.Normalized()
+	if ok {
+		t.Errorf("expected '%v' but got '%v'", false, ok)
+	}
+	if n != v {
+		t.Errorf("expected '%v' but got '%v'", v, n)
+	}
+}
+
+func TestNormalized(t *testing.T) {
+	v := Vec3{1, -2, 3}
+	c := v
+	n, ok := v.Normalized()
+	if !ok {
+		t.Errorf("expected '%v' but got '%v'", true, ok)
+	}
+	if l := n.Len(); math.Abs(l-1) > 1e-12 {
+		t.Errorf("expected length '%v' but got '%v'", 1, l)
+	}
+	if v != c {
+		t.Errorf("expected '%v' to be unchanged but got '%v'", c, v)
+	}
+}
+
 func TestNeg(t *testing.T) {
 	v := Vec3{1, -2, 0}
 	r := Vec3{-1, 2, 0}
@@ -62,6 +219,151 @@ func TestScale(t *testing.T) {
 	}
 }
 
+func TestMulComp(t *testing.T) {
+	v := Vec3{1, -2, 3}
+	v.MulComp(&Vec3{2, 3, 0})
+	r := Vec3{2, -6, 0}
+	if v != r {
+		t.Errorf("expected '%v' but got '%v'", r, v)
+	}
+}
+
+func TestDivComp(t *testing.T) {
+	v := Vec3{1, -6, 3}
+	v.DivComp(&Vec3{2, 3, 0})
+	r := Vec3{0.5, -2, 0}
+	if v != r {
+		t.Errorf("expected '%v' but got '%v'", r, v)
+	}
+}
+
+var approxeqtests = []struct {
+	d, eps float64
+	eq     bool
+}{
+	{0, 0, true},
+	{0.5, 0.5, true},
+	{0.5000001, 0.5, false},
+	{-0.5, 0.5, true},
+	{-0.5000001, 0.5, false},
+}
+
+func TestVec3ApproxEq(t *testing.T) {
+	v := Vec3{1, 2, 3}
+	for _, test := range approxeqtests {
+		w := Vec3{1, 2 + test.d, 3}
+		eq := v.ApproxEq(&w, test.eps)
+		if eq != test.eq {
+			t.Errorf("%v,%v: expected '%v' but got '%v'", w, test.eps, test.eq, eq)
+		}
+	}
+}
+
+var isfinitetests = []struct {
+	c      float64
+	finite bool
+}{
+	{1e300, true},
+	{math.NaN(), false},
+	{math.Inf(1), false},
+	{math.Inf(-1), false},
+}
+
+func TestVec3IsFinite(t *testing.T) {
+	for _, test := range isfinitetests {
+		v := Vec3{1, test.c, 3}
+		if f := v.IsFinite(); f != test.finite {
+			t.Errorf("%v: expected '%v' but got '%v'", v, test.finite, f)
+		}
+	}
+}
+
+func TestQuantize(t *testing.T) {
+	v := Vec3{1.0000001, -2.4999999, 0.0000002}
+	w := Vec3{0.9999998, -2.5000001, -0.0000001}
+	qv := v.Quantize(0.001)
+	qw := w.Quantize(0.001)
+	if qv != qw {
+		t.Errorf("expected '%v' but got '%v'", qv, qw)
+	}
+	m := map[Vec3]bool{qv: true}
+	if !m[qw] {
+		t.Errorf("expected '%v' to be found in map", qw)
+	}
+	u := Vec3{1.26, -0.74, 3}
+	q := u.Quantize(0.5)
+	r := Vec3{1.5, -0.5, 3}
+	if q != r {
+		t.Errorf("expected '%v' but got '%v'", r, q)
+	}
+}
+
+func TestAbs(t *testing.T) {
+	v := Vec3{-1.5, 0, 2}
+	a := *v.Abs()
+	r := Vec3{1.5, 0, 2}
+	if a != r {
+		t.Errorf("expected '%v' but got '%v'", r, a)
+	}
+}
+
+func TestSign(t *testing.T) {
+	v := Vec3{-1.5, 0, 2}
+	s := *v.Sign()
+	r := Vec3{-1, 0, 1}
+	if s != r {
+		t.Errorf("expected '%v' but got '%v'", r, s)
+	}
+}
+
+var roundingtests = []struct {
+	name string
+	f    func(*Vec3) *Vec3
+	r    Vec3
+}{
+	{"floor", (*Vec3).Floor, Vec3{1, -2, 2}},
+	{"ceil", (*Vec3).Ceil, Vec3{2, -1, 3}},
+	{"round", (*Vec3).Round, Vec3{1, -2, 3}},
+}
+
+func TestRounding(t *testing.T) {
+	v := Vec3{1.4, -1.6, 2.5}
+	for _, test := range roundingtests {
+		r := *test.f(&v)
+		if r != test.r {
+			t.Errorf("%v: expected '%v' but got '%v'", test.name, test.r, r)
+		}
+	}
+}
+
+func TestAdded(t *testing.T) {
+	v := Vec3{50, -2, 7}
+	w := Vec3{1, 1, -6}
+	vc, wc := v, w
+	r := Vec3{51, -1, 1}
+	a := *Added(&v, &w)
+	if a != r {
+		t.Errorf("expected '%v' but got '%v'", r, a)
+	}
+	if v != vc || w != wc {
+		t.Errorf("expected '%v' and '%v' to be unchanged but got '%v' and '%v'", vc, wc, v, w)
+	}
+}
+
+func TestSubbed(t *testing.T) {
+	v := Vec3{50, -2, 7}
+	w := Vec3{1, 1, -6}
+	vc, wc := v, w
+	r := Vec3{49, -3, 13}
+	s := *Subbed(&v, &w)
+	if s != r {
+		t.Errorf("expected '%v' but got '%v'", r, s)
+	}
+	if v != vc || w != wc {
+		t.Errorf("expected '%v' and '%v' to be unchanged but got '%v' and '%v'", vc, wc, v, w)
+	}
+}
+
 func TestCross(t *testing.T) {
 	v1 := Vec3{2, 3, 4}
 	v2 := Vec3{5, 6, 7}
@@ -72,59 +374,1663 @@ func TestCross(t *testing.T) {
 	}
 }
 
-func TestNewVec4(t *testing.T) {
-	v := *NewVec4(1, 2, 3)
-	r := Vec4{1, 2, 3, 1}
-	if v != r {
-		t.Errorf("expected '%v' but got '%v'", r, v)
+func TestInto(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	for i := 0; i < 10; i++ {
+		v := *RandVec3Seed(r.Int63())
+		w := *RandVec3Seed(r.Int63())
+		var d Vec3
+		AddedInto(&d, &v, &w)
+		if e := *Added(&v, &w); d != e {
+			t.Errorf("expected '%v' but got '%v'", e, d)
+		}
+		SubbedInto(&d, &v, &w)
+		if e := *Subbed(&v, &w); d != e {
+			t.Errorf("expected '%v' but got '%v'", e, d)
+		}
+		CrossInto(&d, &v, &w)
+		if e := *Cross(&v, &w); d != e {
+			t.Errorf("expected '%v' but got '%v'", e, d)
+		}
+		LerpInto(&d, &v, &w, 0.3)
+		if e := *Lerp(&v, &w, 0.3); d != e {
+			t.Errorf("expected '%v' but got '%v'", e, d)
+		}
+		// The destination can be one of the operands.
+		e := *Cross(&v, &w)
+		CrossInto(&v, &v, &w)
+		if v != e {
+			t.Errorf("expected '%v' but got '%v'", e, v)
+		}
 	}
 }
 
-func TestNorm4(t *testing.T) {
-	v := Vec4{2, 4, 12, 2}
-	r := Vec4{1, 2, 6, 1}
-	v.Norm()
-	if v != r {
-		t.Errorf("expected '%v' but got '%v'", r, v)
+func BenchmarkCross(b *testing.B) {
+	r := rand.New(rand.NewSource(0))
+	v := *RandVec3Seed(r.Int63())
+	w := *RandVec3Seed(r.Int63())
+	p := make([]*Vec3, 1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range p {
+			p[j] = Cross(&v, &w)
+		}
 	}
 }
 
-func TestRandMat(t *testing.T) {
+func BenchmarkCrossInto(b *testing.B) {
 	r := rand.New(rand.NewSource(0))
-	m := *RandMat(r)
-	tm := reflect.TypeOf(m)
-	n := Mat4{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}
-	tn := reflect.TypeOf(n)
-	if tm != tn {
-		t.Errorf("expected '%v' but got '%v'", tn, tm)
+	v := *RandVec3Seed(r.Int63())
+	w := *RandVec3Seed(r.Int63())
+	p := make([]Vec3, 1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range p {
+			CrossInto(&p[j], &v, &w)
+		}
 	}
 }
-func TestMul(t *testing.T) {
-	m := Mat4{0, 3, 0, 1, 6, 3, 5, 3, 7, 4, 8, 7, 3, 6, 0, 3}
-	n := Mat4{9, 0, 4, 10, 4, 7, 0, 5, 6, 5, 8, 7, 9, 10, 7, 10}
-	r := Mat4{21, 31, 7, 25, 123, 76, 85, 140, 190, 138, 141, 216, 78, 72, 33, 90}
-	m.Mul(&n)
-	if m != r {
-		t.Errorf("expected '%v' but got '%v'", r, m)
+
+var dottests = []struct {
+	v, w Vec3
+	dot  float64
+}{
+	{Vec3{1, 0, 0}, Vec3{0, 1, 0}, 0},
+	{Vec3{0, 0, 1}, Vec3{0, 0, 1}, 1},
+	{Vec3{0, 1, 0}, Vec3{0, -1, 0}, -1},
+	{Vec3{1, 2, 3}, Vec3{4, -5, 6}, 12},
+}
+
+func TestDot(t *testing.T) {
+	for _, test := range dottests {
+		d := Dot(&test.v, &test.w)
+		if d != test.dot {
+			t.Errorf("expected '%v' but got '%v'", test.dot, d)
+		}
 	}
 }
 
-func TestTransf(t *testing.T) {
-	m := Mat4{1, 3, 2, 2, 9, 10, 1, 9, 0, 4, 5, 1, 6, 8, 5, 8}
-	v := Vec4{10, 7, 0, 8}
-	r := Vec4{47, 232, 36, 180}
-	p := *m.Transf(&v)
-	if p != r {
-		t.Errorf("expected '%v' but got '%v'", r, p)
+func TestDist(t *testing.T) {
+	v := Vec3{0, 0, 0}
+	w := Vec3{3, 4, 0}
+	d := Dist(&v, &w)
+	if d != 5 {
+		t.Errorf("expected '%v' but got '%v'", 5, d)
 	}
 }
 
-func BenchmarkMul(b *testing.B) {
-	r := rand.New(rand.NewSource(0))
-	m := RandMat(r)
-	n := RandMat(r)
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		m.Mul(n)
+func TestDistSq(t *testing.T) {
+	v := Vec3{0, 0, 0}
+	w := Vec3{3, 4, 0}
+	d := DistSq(&v, &w)
+	if d != 25 {
+		t.Errorf("expected '%v' but got '%v'", 25, d)
+	}
+}
+
+var lerptests = []struct {
+	t       float64
+	r, rcla Vec3
+}{
+	{0, Vec3{1, 2, 3}, Vec3{1, 2, 3}},
+	{1, Vec3{3, -2, 7}, Vec3{3, -2, 7}},
+	{0.5, Vec3{2, 0, 5}, Vec3{2, 0, 5}},
+	{2, Vec3{5, -6, 11}, Vec3{3, -2, 7}},
+	{-1, Vec3{-1, 6, -1}, Vec3{1, 2, 3}},
+}
+
+func TestLerp(t *testing.T) {
+	a := Vec3{1, 2, 3}
+	b := Vec3{3, -2, 7}
+	for _, test := range lerptests {
+		l := *Lerp(&a, &b, test.t)
+		if l != test.r {
+			t.Errorf("t=%v: expected '%v' but got '%v'", test.t, test.r, l)
+		}
+	}
+}
+
+func TestLerpClamped(t *testing.T) {
+	a := Vec3{1, 2, 3}
+	b := Vec3{3, -2, 7}
+	for _, test := range lerptests {
+		l := *LerpClamped(&a, &b, test.t)
+		if l != test.rcla {
+			t.Errorf("t=%v: expected '%v' but got '%v'", test.t, test.rcla, l)
+		}
+	}
+}
+
+var reflecttests = []struct {
+	d, n, r Vec3
+}{
+	{Vec3{1, -1, 0}, Vec3{0, 1, 0}, Vec3{1, 1, 0}},
+	{Vec3{0, 0, -2}, Vec3{0, 0, 1}, Vec3{0, 0, 2}},
+	{Vec3{3, 0, 0}, Vec3{0, 1, 0}, Vec3{3, 0, 0}},
+}
+
+func TestReflect(t *testing.T) {
+	for _, test := range reflecttests {
+		r := *Reflect(&test.d, &test.n)
+		if r != test.r {
+			t.Errorf("expected '%v' but got '%v'", test.r, r)
+		}
+	}
+}
+
+var projecttests = []struct {
+	v, onto, r Vec3
+}{
+	{Vec3{2, 2, 0}, Vec3{1, 0, 0}, Vec3{2, 0, 0}},
+	{Vec3{2, 2, 0}, Vec3{0, -3, 0}, Vec3{0, 2, 0}},
+	{Vec3{1, 2, 3}, Vec3{0, 0, 0}, Vec3{0, 0, 0}},
+}
+
+func TestProject(t *testing.T) {
+	for _, test := range projecttests {
+		p := *Project(&test.v, &test.onto)
+		if p != test.r {
+			t.Errorf("expected '%v' but got '%v'", test.r, p)
+		}
+	}
+}
+
+var angletests = []struct {
+	v, w  Vec3
+	angle float64
+}{
+	{Vec3{1, 0, 0}, Vec3{0, 2, 0}, math.Pi / 2},
+	{Vec3{1, 0, 0}, Vec3{-1, 0, 0}, math.Pi},
+	{Vec3{0.1, 0.2, 0.3}, Vec3{0.1, 0.2, 0.3000000001}, 0},
+	{Vec3{3, 3, 3}, Vec3{1, 1, 1}, 0},
+	{Vec3{0, 0, 0}, Vec3{1, 1, 1}, 0},
+}
+
+func TestAngle(t *testing.T) {
+	for _, test := range angletests {
+		a := Angle(&test.v, &test.w)
+		if math.IsNaN(a) || math.Abs(a-test.angle) > 1e-6 {
+			t.Errorf("expected '%v' but got '%v'", test.angle, a)
+		}
+	}
+}
+
+var rotatetowardstests = []struct {
+	from, to Vec3
+	max      float64
+	r        Vec3
+}{
+	{Vec3{1, 0, 0}, Vec3{0, 2, 0}, math.Pi / 4, Vec3{math.Sqrt2 / 2, math.Sqrt2 / 2, 0}},
+	{Vec3{1, 0, 0}, Vec3{0, 2, 0}, math.Pi, Vec3{0, 1, 0}},
+	{Vec3{1, 0, 0}, Vec3{0, 2, 0}, math.Pi / 2, Vec3{0, 1, 0}},
+	{Vec3{0, 0, 3}, Vec3{0, 0, 1}, 0.1, Vec3{0, 0, 1}},
+	{Vec3{2, 0, 0}, Vec3{0, 1, 0}, -0.5, Vec3{1, 0, 0}},
+}
+
+func TestRotateTowards(t *testing.T) {
+	for _, test := range rotatetowardstests {
+		r := RotateTowards(&test.from, &test.to, test.max)
+		if !r.ApproxEq(&test.r, eps) {
+			t.Errorf("expected '%v' but got '%v'", test.r, *r)
+		}
+	}
+}
+
+func TestRotateTowardsOpposite(t *testing.T) {
+	from := Vec3{0, 1, 0}
+	to := Vec3{0, -1, 0}
+	r := RotateTowards(&from, &to, math.Pi/2)
+	if a := Angle(r, &from); math.Abs(a-math.Pi/2) > eps {
+		t.Errorf("expected angle '%v' but got '%v'", math.Pi/2, a)
+	}
+	if l := r.Len(); math.Abs(l-1) > eps {
+		t.Errorf("expected length '%v' but got '%v'", 1, l)
+	}
+}
+
+var slerpvectests = []struct {
+	a, b Vec3
+	t    float64
+	r    Vec3
+}{
+	{Vec3{1, 0, 0}, Vec3{0, 1, 0}, 0.5, Vec3{math.Sqrt2 / 2, math.Sqrt2 / 2, 0}},
+	{Vec3{2, 0, 0}, Vec3{0, 0, 3}, 0.5, Vec3{math.Sqrt2 / 2, 0, math.Sqrt2 / 2}},
+	{Vec3{1, 0, 0}, Vec3{0, 1, 0}, 0, Vec3{1, 0, 0}},
+	{Vec3{1, 0, 0}, Vec3{0, 1, 0}, 1, Vec3{0, 1, 0}},
+	{Vec3{1, 0, 0}, Vec3{-1, 0, 0}, 1, Vec3{-1, 0, 0}},
+	{Vec3{0, 0, 1}, Vec3{0, 1e-9, 1}, 0.5, Vec3{0, 0.5e-9, 1}},
+}
+
+func TestSlerpVec(t *testing.T) {
+	for _, test := range slerpvectests {
+		r := SlerpVec(&test.a, &test.b, test.t)
+		if !r.ApproxEq(&test.r, eps) {
+			t.Errorf("expected '%v' but got '%v'", test.r, *r)
+		}
+	}
+}
+
+func TestSlerpVecOpposite(t *testing.T) {
+	a := Vec3{0, 1, 0}
+	b := Vec3{0, -1, 0}
+	r := SlerpVec(&a, &b, 0.5)
+	if g := Angle(r, &a); math.Abs(g-math.Pi/2) > eps {
+		t.Errorf("expected angle '%v' but got '%v'", math.Pi/2, g)
+	}
+	if l := r.Len(); math.Abs(l-1) > eps {
+		t.Errorf("expected length '%v' but got '%v'", 1, l)
+	}
+}
+
+var spheremidpointtests = []struct {
+	a, b, r Vec3
+}{
+	{Vec3{1, 0, 0}, Vec3{0, 1, 0}, Vec3{math.Sqrt2 / 2, math.Sqrt2 / 2, 0}},
+	{Vec3{0, 0, 1}, Vec3{0, 0, 1}, Vec3{0, 0, 1}},
+	{Vec3{1, 0, 0}, Vec3{0, 0, -1}, Vec3{math.Sqrt2 / 2, 0, -math.Sqrt2 / 2}},
+}
+
+func TestSphereMidpoint(t *testing.T) {
+	for _, test := range spheremidpointtests {
+		m := SphereMidpoint(&test.a, &test.b)
+		if !m.ApproxEq(&test.r, eps) {
+			t.Errorf("expected '%v' but got '%v'", test.r, *m)
+		}
+		if da, db := AngularDist(m, &test.a), AngularDist(m, &test.b); math.Abs(da-db) > eps {
+			t.Errorf("expected equal distances but got '%v' and '%v'", da, db)
+		}
+	}
+}
+
+func TestSphereMidpointAntipodal(t *testing.T) {
+	a := Vec3{0, 1, 0}
+	b := Vec3{0, -1, 0}
+	m := SphereMidpoint(&a, &b)
+	if d := AngularDist(m, &a); math.Abs(d-math.Pi/2) > eps {
+		t.Errorf("expected '%v' but got '%v'", math.Pi/2, d)
+	}
+	if l := m.Len(); math.Abs(l-1) > eps {
+		t.Errorf("expected length '%v' but got '%v'", 1, l)
+	}
+}
+
+var angulardisttests = []struct {
+	a, b Vec3
+	d    float64
+}{
+	{Vec3{1, 0, 0}, Vec3{0, 1, 0}, math.Pi / 2},
+	{Vec3{1, 0, 0}, Vec3{1, 0, 0}, 0},
+	{Vec3{1, 0, 0}, Vec3{-1, 0, 0}, math.Pi},
+	{Vec3{0, 1, 0}, Vec3{0, 0.5, math.Sqrt(3) / 2}, math.Pi / 3},
+	{Vec3{1, 0, 0}, Vec3{math.Cos(1e-10), math.Sin(1e-10), 0}, 1e-10},
+}
+
+func TestAngularDist(t *testing.T) {
+	for _, test := range angulardisttests {
+		d := AngularDist(&test.a, &test.b)
+		if math.Abs(d-test.d) > 1e-15 {
+			t.Errorf("expected '%v' but got '%v'", test.d, d)
+		}
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	a := Vec3{1, -2, 3}
+	b := Vec3{0, 5, 3}
+	min := *Min(&a, &b)
+	rmin := Vec3{0, -2, 3}
+	if min != rmin {
+		t.Errorf("expected '%v' but got '%v'", rmin, min)
+	}
+	max := *Max(&a, &b)
+	rmax := Vec3{1, 5, 3}
+	if max != rmax {
+		t.Errorf("expected '%v' but got '%v'", rmax, max)
+	}
+}
+
+func TestClamp(t *testing.T) {
+	v := Vec3{5, 0.5, -3}
+	v.Clamp(&Vec3{0, 0, -1}, &Vec3{1, 1, 1})
+	r := Vec3{1, 0.5, -1}
+	if v != r {
+		t.Errorf("expected '%v' but got '%v'", r, v)
+	}
+}
+
+var swizzletests = []struct {
+	i, j, k int
+	r       Vec3
+}{
+	{0, 1, 2, Vec3{1, 2, 3}},
+	{0, 2, 1, Vec3{1, 3, 2}},
+	{2, 1, 0, Vec3{3, 2, 1}},
+	{1, 1, 1, Vec3{2, 2, 2}},
+}
+
+func TestSwizzle(t *testing.T) {
+	v := Vec3{1, 2, 3}
+	for _, test := range swizzletests {
+		r := *v.Swizzle(test.i, test.j, test.k)
+		if r != test.r {
+			t.Errorf("expected '%v' but got '%v'", test.r, r)
+		}
+	}
+}
+
+func TestSwizzleOutOfRange(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("expected panic for index out of range")
+		}
+	}()
+	v := Vec3{1, 2, 3}
+	v.Swizzle(0, 3, 1)
+}
+
+var reflectpointtests = []struct {
+	v, c, r Vec3
+}{
+	{Vec3{3, 0, 0}, Vec3{1, 0, 0}, Vec3{-1, 0, 0}},
+	{Vec3{1, 2, 3}, Vec3{0, 0, 0}, Vec3{-1, -2, -3}},
+	{Vec3{1, 2, 3}, Vec3{1, 2, 3}, Vec3{1, 2, 3}},
+	{Vec3{-1, 4, 0.5}, Vec3{2, 2, 2}, Vec3{5, 0, 3.5}},
+}
+
+func TestReflectPoint(t *testing.T) {
+	for _, test := range reflectpointtests {
+		r := *test.v.ReflectPoint(&test.c)
+		if r != test.r {
+			t.Errorf("expected '%v' but got '%v'", test.r, r)
+		}
+	}
+}
+
+var weightedmeantests = []struct {
+	weights []float64
+	r       Vec3
+}{
+	{[]float64{1, 1, 1}, Vec3{1, 2, 3}},
+	{[]float64{2, 2, 2}, Vec3{1, 2, 3}},
+	{[]float64{1, 0, 0}, Vec3{0, 0, 0}},
+	{[]float64{0, 1, 3}, Vec3{2.25, 1.5, 5.25}},
+}
+
+func TestWeightedMean(t *testing.T) {
+	points := []Vec3{{0, 0, 0}, {0, 6, 3}, {3, 0, 6}}
+	for _, test := range weightedmeantests {
+		m, err := WeightedMean(points, test.weights)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !m.ApproxEq(&test.r, eps) {
+			t.Errorf("%v: expected '%v' but got '%v'", test.weights, test.r, *m)
+		}
+	}
+}
+
+func TestWeightedMeanInvalid(t *testing.T) {
+	points := []Vec3{{1, 2, 3}, {4, 5, 6}}
+	if _, err := WeightedMean(points, []float64{1}); err == nil {
+		t.Errorf("expected error for mismatched lengths")
+	}
+	if _, err := WeightedMean(points, []float64{1, -1}); err == nil {
+		t.Errorf("expected error for zero weights")
+	}
+}
+
+func TestRandVec3Seed(t *testing.T) {
+	v := *RandVec3Seed(42)
+	w := *RandVec3Seed(42)
+	if v != w {
+		t.Errorf("expected '%v' but got '%v'", v, w)
+	}
+	for _, c := range v {
+		if c < 0 || c >= 1 {
+			t.Errorf("expected component in [0,1) but got '%v'", c)
+		}
+	}
+	if u := *RandVec3Seed(43); u == v {
+		t.Errorf("expected different vectors for different seeds but got '%v'", u)
+	}
+}
+
+func TestRandUnitVec3(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	n := 10000
+	var mean, meanAbs Vec3
+	for i := 0; i < n; i++ {
+		v := RandUnitVec3(r)
+		if l := v.Len(); math.Abs(l-1) > eps {
+			t.Fatalf("expected length '%v' but got '%v'", 1, l)
+		}
+		mean.Add(v)
+		for j := range v {
+			meanAbs[j] += math.Abs(v[j])
+		}
+	}
+	mean.Scale(1 / float64(n))
+	meanAbs.Scale(1 / float64(n))
+	for j := 0; j < 3; j++ {
+		if math.Abs(mean[j]) > 0.03 {
+			t.Errorf("expected mean '%v' but got '%v'", 0, mean)
+		}
+		// The mean of |x| for uniform points on the unit sphere is 1/2.
+		if math.Abs(meanAbs[j]-0.5) > 0.03 {
+			t.Errorf("expected mean of absolute values '%v' but got '%v'", 0.5, meanAbs)
+		}
+	}
+}
+
+var sphericaltests = []Vec3{
+	{1, 2, 3},
+	{-4, 0.5, -1},
+	{0, 0, 1},
+	{1, 0, 0},
+	{0, 3, 0},
+	{0, -2, 0},
+	{0, 0, 0},
+}
+
+func TestSpherical(t *testing.T) {
+	for _, test := range sphericaltests {
+		r, theta, phi := test.ToSpherical()
+		v := FromSpherical(r, theta, phi)
+		if !v.ApproxEq(&test, eps) {
+			t.Errorf("expected '%v' but got '%v'", test, *v)
+		}
+	}
+}
+
+func TestToSpherical(t *testing.T) {
+	v := Vec3{1, 0, 0}
+	r, theta, phi := v.ToSpherical()
+	if r != 1 || math.Abs(theta-math.Pi/2) > eps || math.Abs(phi-math.Pi/2) > eps {
+		t.Errorf("expected '%v' but got '%v'", []float64{1, math.Pi / 2, math.Pi / 2}, []float64{r, theta, phi})
+	}
+}
+
+var closestpointtests = []struct {
+	p, a, b, r Vec3
+}{
+	{Vec3{1, 5, 0}, Vec3{0, 0, 0}, Vec3{4, 0, 0}, Vec3{1, 0, 0}},
+	{Vec3{7, -1, 2}, Vec3{0, 0, 0}, Vec3{4, 0, 0}, Vec3{4, 0, 0}},
+	{Vec3{-3, 1, 0}, Vec3{0, 0, 0}, Vec3{4, 0, 0}, Vec3{0, 0, 0}},
+	{Vec3{-1, 2, 0}, Vec3{-1, 0, 0}, Vec3{1, 2, 0}, Vec3{0, 1, 0}},
+	{Vec3{5, 5, 5}, Vec3{1, 2, 3}, Vec3{1, 2, 3}, Vec3{1, 2, 3}},
+}
+
+func TestClosestPointOnSegment(t *testing.T) {
+	for _, test := range closestpointtests {
+		c := ClosestPointOnSegment(&test.p, &test.a, &test.b)
+		if !c.ApproxEq(&test.r, eps) {
+			t.Errorf("expected '%v' but got '%v'", test.r, *c)
+		}
+	}
+}
+
+func TestNewVec4(t *testing.T) {
+	v := *NewVec4(1, 2, 3)
+	r := Vec4{1, 2, 3, 1}
+	if v != r {
+		t.Errorf("expected '%v' but got '%v'", r, v)
+	}
+}
+
+func TestNorm4(t *testing.T) {
+	v := Vec4{2, 4, 12, 2}
+	r := Vec4{1, 2, 6, 1}
+	if ok := v.Norm(); !ok {
+		t.Errorf("expected '%v' but got '%v'", true, ok)
+	}
+	if v != r {
+		t.Errorf("expected '%v' but got '%v'", r, v)
+	}
+}
+
+func TestNorm4Zero(t *testing.T) {
+	v := Vec4{2, 4, 12, 0}
+	r := v
+	if ok := v.Norm(); ok {
+		t.Errorf("expected '%v' but got '%v'", false, ok)
+	}
+	if v != r {
+		t.Errorf("expected '%v' to be unchanged but got '%v'", r, v)
+	}
+}
+
+func TestAdd4(t *testing.T) {
+	v := Vec4{1, 2, 3, 1}
+	v.Add(&Vec4{4, -5, 6, 1})
+	r := Vec4{5, -3, 9, 2}
+	if v != r {
+		t.Errorf("expected '%v' but got '%v'", r, v)
+	}
+}
+
+func TestSub4(t *testing.T) {
+	v := Vec4{1, 2, 3, 1}
+	v.Sub(&Vec4{4, -5, 6, 1})
+	r := Vec4{-3, 7, -3, 0}
+	if v != r {
+		t.Errorf("expected '%v' but got '%v'", r, v)
+	}
+}
+
+func TestScale4(t *testing.T) {
+	v := Vec4{1, 2, 3, 1}
+	v.Scale(0.5)
+	r := Vec4{0.5, 1, 1.5, 0.5}
+	if v != r {
+		t.Errorf("expected '%v' but got '%v'", r, v)
+	}
+}
+
+func TestDot4(t *testing.T) {
+	v := Vec4{1, 2, 3, 1}
+	if d := v.Dot(&Vec4{4, -5, 6, 2}); d != 14 {
+		t.Errorf("expected '%v' but got '%v'", 14, d)
+	}
+}
+
+func TestToVec4(t *testing.T) {
+	v := Vec3{1, 2, 3}
+	w := *v.ToVec4()
+	r := Vec4{1, 2, 3, 1}
+	if w != r {
+		t.Errorf("expected '%v' but got '%v'", r, w)
+	}
+}
+
+var tovec3tests = []struct {
+	v Vec4
+	r Vec3
+}{
+	{Vec4{2, 4, -6, 2}, Vec3{1, 2, -3}},
+	{Vec4{1, 2, 3, 1}, Vec3{1, 2, 3}},
+	{Vec4{1, 2, 3, 0}, Vec3{1, 2, 3}},
+}
+
+func TestToVec3(t *testing.T) {
+	for _, test := range tovec3tests {
+		v := *test.v.ToVec3()
+		if v != test.r {
+			t.Errorf("expected '%v' but got '%v'", test.r, v)
+		}
+	}
+}
+
+func TestPerspective(t *testing.T) {
+	v := Vec4{1, -2, 4, 2}
+	p := v.Perspective()
+	r := Vec3{0.5, -1, 2}
+	if p == nil || *p != r {
+		t.Errorf("expected '%v' but got '%v'", r, p)
+	}
+	w := Vec4{1, 2, 3, 0}
+	if p := w.Perspective(); p != nil {
+		t.Errorf("expected '%v' but got '%v'", nil, *p)
+	}
+}
+
+func TestRandMat(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	m := *RandMat(r)
+	tm := reflect.TypeOf(m)
+	n := Mat4{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}
+	tn := reflect.TypeOf(n)
+	if tm != tn {
+		t.Errorf("expected '%v' but got '%v'", tn, tm)
+	}
+}
+
+func TestRandMatSeed(t *testing.T) {
+	m := *RandMatSeed(42)
+	n := *RandMatSeed(42)
+	if m != n {
+		t.Errorf("expected '%v' but got '%v'", m, n)
+	}
+	if o := *RandMatSeed(43); o == m {
+		t.Errorf("expected different matrices for different seeds but got '%v'", o)
+	}
+}
+
+func TestMat4Clone(t *testing.T) {
+	m := RandMatSeed(0)
+	c := m.Clone()
+	if *c != *m {
+		t.Errorf("expected '%v' but got '%v'", *m, *c)
+	}
+	c[0] = 5
+	if m[0] == 5 {
+		t.Errorf("expected clone to not alias original but got '%v'", *m)
+	}
+}
+
+func TestSetIdentity(t *testing.T) {
+	m := RandMatSeed(0)
+	m.SetIdentity()
+	if *m != *IdentityMat() {
+		t.Errorf("expected '%v' but got '%v'", *IdentityMat(), *m)
+	}
+}
+
+func TestOuter(t *testing.T) {
+	v := Vec3{1, 2, 3}
+	w := Vec3{-4, 5, 0.5}
+	m := Outer(&v, &w)
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			r := 0.0
+			if i < 3 && j < 3 {
+				r = v[i] * w[j]
+			}
+			if a := m.At(i, j); a != r {
+				t.Errorf("expected '%v' at (%v,%v) but got '%v'", r, i, j, a)
+			}
+		}
+	}
+}
+
+var skewtests = []struct {
+	v, w Vec3
+}{
+	{Vec3{1, 0, 0}, Vec3{0, 1, 0}},
+	{Vec3{1, 2, 3}, Vec3{-4, 5, 0.5}},
+	{Vec3{2, 2, 2}, Vec3{1, 1, 1}},
+	{Vec3{0, 0, 0}, Vec3{7, -8, 9}},
+}
+
+func TestSkewMat(t *testing.T) {
+	for _, test := range skewtests {
+		m := SkewMat(&test.v)
+		p := *m.TransfDir(&test.w)
+		r := *Cross(&test.v, &test.w)
+		if p != r {
+			t.Errorf("expected '%v' but got '%v'", r, p)
+		}
+		n := Transposed(m)
+		n.ScaleBy(-1)
+		if *n != *m {
+			t.Errorf("expected skew-symmetric '%v' but got '%v'", *m, *n)
+		}
+	}
+}
+
+func TestMat4FromRows(t *testing.T) {
+	r := [4]Vec4{{1, 2, 3, 4}, {5, 6, 7, 8}, {9, 10, 11, 12}, {13, 14, 15, 16}}
+	m := Mat4FromRows(&r[0], &r[1], &r[2], &r[3])
+	for i := range r {
+		row := m.Row(i)
+		if row != r[i] {
+			t.Errorf("expected row %v '%v' but got '%v'", i, r[i], row)
+		}
+	}
+}
+
+func TestMat4FromCols(t *testing.T) {
+	x := Vec4{0, 1, 0, 0}
+	y := Vec4{-1, 0, 0, 0}
+	z := Vec4{0, 0, 1, 0}
+	o := Vec4{5, 6, 7, 1}
+	m := Mat4FromCols(&x, &y, &z, &o)
+	r := Mul(TranslationMat(5, 6, 7), RotZMat(math.Pi/2))
+	if !m.ApproxEq(r, eps) {
+		t.Errorf("expected '%v' but got '%v'", *r, *m)
+	}
+	n := *Mat4FromRows(&x, &y, &z, &o)
+	n.Transpose()
+	if n != *m {
+		t.Errorf("expected '%v' but got '%v'", *m, n)
+	}
+}
+
+func TestIdentityMat(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	n := RandMat(r)
+	m := IdentityMat()
+	m.Mul(n)
+	if !m.ApproxEq(n, eps) {
+		t.Errorf("expected '%v' but got '%v'", *n, *m)
+	}
+}
+
+var translationtests = []struct {
+	x, y, z float64
+	v, r    Vec4
+}{
+	{1, 2, 3, Vec4{0, 0, 0, 1}, Vec4{1, 2, 3, 1}},
+	{-4, 0, 2.5, Vec4{1, 2, 3, 1}, Vec4{-3, 2, 5.5, 1}},
+}
+
+func TestTranslationMat(t *testing.T) {
+	for _, test := range translationtests {
+		m := TranslationMat(test.x, test.y, test.z)
+		p := *m.Transf(&test.v)
+		if p != test.r {
+			t.Errorf("expected '%v' but got '%v'", test.r, p)
+		}
+	}
+}
+
+var scaletests = []struct {
+	sx, sy, sz float64
+	v, r       Vec4
+}{
+	{2, 3, 4, Vec4{1, 1, 1, 1}, Vec4{2, 3, 4, 1}},
+	{0.5, 0.5, 0.5, Vec4{2, -4, 6, 1}, Vec4{1, -2, 3, 1}},
+}
+
+func TestScaleMat(t *testing.T) {
+	for _, test := range scaletests {
+		m := ScaleMat(test.sx, test.sy, test.sz)
+		p := *m.Transf(&test.v)
+		if p != test.r {
+			t.Errorf("expected '%v' but got '%v'", test.r, p)
+		}
+	}
+}
+
+var rottests = []struct {
+	name string
+	rot  func(float64) *Mat4
+	v, r Vec4
+}{
+	{"x", RotXMat, Vec4{0, 1, 0, 1}, Vec4{0, 0, 1, 1}},
+	{"y", RotYMat, Vec4{0, 0, 1, 1}, Vec4{1, 0, 0, 1}},
+	{"z", RotZMat, Vec4{1, 0, 0, 1}, Vec4{0, 1, 0, 1}},
+}
+
+func TestRotMat(t *testing.T) {
+	for _, test := range rottests {
+		m := test.rot(math.Pi / 2)
+		p := m.Transf(&test.v)
+		if !vec4ApproxEq(p, &test.r, eps) {
+			t.Errorf("%v: expected '%v' but got '%v'", test.name, test.r, *p)
+		}
+	}
+}
+
+func TestShearMat(t *testing.T) {
+	v := Vec4{1, 2, 3, 1}
+	for i := 0; i < 6; i++ {
+		var s [6]float64
+		s[i] = 2
+		m := ShearMat(s[0], s[1], s[2], s[3], s[4], s[5])
+		p := *m.Transf(&v)
+		// Parameter i shifts axis i/2 by one of the other two axes.
+		a := i / 2
+		b := []int{1, 2, 0, 2, 0, 1}[i]
+		r := v
+		r[a] += 2 * v[b]
+		if p != r {
+			t.Errorf("parameter %v: expected '%v' but got '%v'", i, r, p)
+		}
+	}
+}
+
+var rotaxistests = []struct {
+	axis Vec3
+	rot  func(float64) *Mat4
+}{
+	{Vec3{1, 0, 0}, RotXMat},
+	{Vec3{0, 2, 0}, RotYMat},
+	{Vec3{0, 0, 1}, RotZMat},
+}
+
+func TestRotAxisMat(t *testing.T) {
+	for _, test := range rotaxistests {
+		for _, rad := range []float64{math.Pi / 2, -0.3, 2} {
+			m := RotAxisMat(&test.axis, rad)
+			r := test.rot(rad)
+			if !m.ApproxEq(r, eps) {
+				t.Errorf("axis %v: expected '%v' but got '%v'", test.axis, *r, *m)
+			}
+		}
+	}
+}
+
+func TestRotAxisMatZero(t *testing.T) {
+	m := RotAxisMat(&Vec3{0, 0, 0}, 1)
+	r := IdentityMat()
+	if *m != *r {
+		t.Errorf("expected '%v' but got '%v'", *r, *m)
+	}
+}
+
+var perspectivetests = []struct {
+	v Vec4
+	z float64
+}{
+	{Vec4{0, 0, -2, 1}, -1},
+	{Vec4{1, 1, -2, 1}, -1},
+	{Vec4{0, 0, -20, 1}, 1},
+}
+
+func TestPerspectiveMat(t *testing.T) {
+	m := PerspectiveMat(math.Pi/2, 2, 2, 20)
+	for _, test := range perspectivetests {
+		p := m.Transf(&test.v)
+		p.Norm()
+		if math.Abs(p[2]-test.z) > eps {
+			t.Errorf("expected z '%v' but got '%v'", test.z, p[2])
+		}
+	}
+}
+
+func TestPerspectiveMatInvalid(t *testing.T) {
+	if m := PerspectiveMat(1, 0, 1, 10); m != nil {
+		t.Errorf("expected '%v' but got '%v'", nil, *m)
+	}
+	if m := PerspectiveMat(1, 1, 5, 5); m != nil {
+		t.Errorf("expected '%v' but got '%v'", nil, *m)
+	}
+}
+
+func TestEulerToMat4(t *testing.T) {
+	for _, a := range [][3]float64{{0.1, 0.2, 0.3}, {-1, 2, -3}, {math.Pi / 2, 0, 1}} {
+		m := EulerToMat4(a[0], a[1], a[2])
+		r := RotYMat(a[1])
+		r.Mul(RotXMat(a[0]))
+		r.Mul(RotZMat(a[2]))
+		if !m.ApproxEq(r, eps) {
+			t.Errorf("expected '%v' but got '%v'", *r, *m)
+		}
+	}
+	v := EulerToMat4(0.3, 0, 0).Transf(&Vec4{0, 0, -1, 1})
+	if v[1] <= 0 {
+		t.Errorf("expected positive pitch to look up but got '%v'", *v)
+	}
+}
+
+var eulertests = [][3]float64{
+	{0.1, 0.2, 0.3},
+	{-1, 2, -3},
+	{1.5, -0.4, 0.9},
+	{0, math.Pi / 2, 0},
+}
+
+func TestToEuler(t *testing.T) {
+	for _, test := range eulertests {
+		p, y, r := EulerToMat4(test[0], test[1], test[2]).ToEuler()
+		a := [3]float64{p, y, r}
+		for i := range a {
+			if math.Abs(a[i]-test[i]) > eps {
+				t.Errorf("expected '%v' but got '%v'", test, a)
+				break
+			}
+		}
+	}
+}
+
+func TestToEulerGimbalLock(t *testing.T) {
+	m := EulerToMat4(math.Pi/2, 0.3, 0.5)
+	p, y, r := m.ToEuler()
+	if math.Abs(p-math.Pi/2) > 1e-6 || r != 0 {
+		t.Errorf("expected pitch '%v' and roll '%v' but got '%v' and '%v'", math.Pi/2, 0, p, r)
+	}
+	n := EulerToMat4(p, y, r)
+	if !n.ApproxEq(m, 1e-6) {
+		t.Errorf("expected '%v' but got '%v'", *m, *n)
+	}
+}
+
+var orthotests = []struct {
+	l, r, b, t, n, f float64
+	v, p             Vec4
+}{
+	{-2, 6, -1, 3, 1, 11, Vec4{2, 1, -6, 1}, Vec4{0, 0, 0, 1}},
+	{-2, 6, -1, 3, 1, 11, Vec4{-2, -1, -1, 1}, Vec4{-1, -1, -1, 1}},
+	{-2, 6, -1, 3, 1, 11, Vec4{6, 3, -11, 1}, Vec4{1, 1, 1, 1}},
+	{0, 800, 600, 0, -1, 1, Vec4{0, 0, 0, 1}, Vec4{-1, 1, 0, 1}},
+	{0, 800, 600, 0, -1, 1, Vec4{800, 600, 0, 1}, Vec4{1, -1, 0, 1}},
+}
+
+func TestOrthoMat(t *testing.T) {
+	for _, test := range orthotests {
+		m := OrthoMat(test.l, test.r, test.b, test.t, test.n, test.f)
+		p := m.Transf(&test.v)
+		if !vec4ApproxEq(p, &test.p, eps) {
+			t.Errorf("expected '%v' but got '%v'", test.p, *p)
+		}
+	}
+}
+
+func TestOrthoMatInvalid(t *testing.T) {
+	if m := OrthoMat(1, 1, 0, 1, 0, 1); m != nil {
+		t.Errorf("expected '%v' but got '%v'", nil, *m)
+	}
+}
+
+var lookattests = []struct {
+	eye, center, up Vec3
+}{
+	{Vec3{0, 0, 0}, Vec3{0, 0, -1}, Vec3{0, 1, 0}},
+	{Vec3{1, 2, 3}, Vec3{-4, 0, 7}, Vec3{0, 1, 0}},
+	{Vec3{0, 5, 0}, Vec3{0, 0, 0}, Vec3{0, 1, 0}},
+}
+
+func TestLookAt(t *testing.T) {
+	for _, test := range lookattests {
+		m := LookAt(&test.eye, &test.center, &test.up)
+		c := test.center
+		c.Sub(&test.eye)
+		d := c.Len()
+		p := m.Transf(NewVec4(test.center[0], test.center[1], test.center[2]))
+		r := Vec4{0, 0, -d, 1}
+		if !vec4ApproxEq(p, &r, eps) {
+			t.Errorf("expected '%v' but got '%v'", r, *p)
+		}
+		if math.Abs(m.Det()-1) > eps {
+			t.Errorf("expected determinant '%v' but got '%v'", 1, m.Det())
+		}
+	}
+}
+
+var viewporttests = []struct {
+	flipY bool
+	v, r  Vec4
+}{
+	{false, Vec4{-1, -1, -1, 1}, Vec4{10, 20, 0, 1}},
+	{false, Vec4{1, 1, 1, 1}, Vec4{810, 620, 1, 1}},
+	{false, Vec4{0, 0, 0, 1}, Vec4{410, 320, 0.5, 1}},
+	{true, Vec4{-1, 1, 0, 1}, Vec4{10, 20, 0.5, 1}},
+	{true, Vec4{1, -1, 0, 1}, Vec4{810, 620, 0.5, 1}},
+}
+
+func TestViewportMat(t *testing.T) {
+	for _, test := range viewporttests {
+		m := ViewportMat(10, 20, 800, 600, test.flipY)
+		p := *m.Transf(&test.v)
+		if p != test.r {
+			t.Errorf("expected '%v' but got '%v'", test.r, p)
+		}
+	}
+}
+
+var zuptoyuptests = []struct {
+	v, r Vec4
+}{
+	{Vec4{1, 0, 0, 1}, Vec4{1, 0, 0, 1}},
+	{Vec4{0, 1, 0, 1}, Vec4{0, 0, -1, 1}},
+	{Vec4{0, 0, 1, 1}, Vec4{0, 1, 0, 1}},
+	{Vec4{1, 2, 3, 0}, Vec4{1, 3, -2, 0}},
+}
+
+func TestZUpToYUp(t *testing.T) {
+	m := ZUpToYUp()
+	for _, test := range zuptoyuptests {
+		p := *m.Transf(&test.v)
+		if p != test.r {
+			t.Errorf("expected '%v' but got '%v'", test.r, p)
+		}
+	}
+}
+
+func TestZUpYUpRoundTrip(t *testing.T) {
+	m := ZUpToYUp()
+	m.Mul(YUpToZUp())
+	if !m.ApproxEq(IdentityMat(), 0) {
+		t.Errorf("expected '%v' but got '%v'", *IdentityMat(), *m)
+	}
+	m = YUpToZUp()
+	m.Mul(ZUpToYUp())
+	if !m.ApproxEq(IdentityMat(), 0) {
+		t.Errorf("expected '%v' but got '%v'", *IdentityMat(), *m)
+	}
+}
+
+var billboardtests = []struct {
+	pos, cam, up Vec3
+}{
+	{Vec3{0, 0, 0}, Vec3{0, 0, 5}, Vec3{0, 1, 0}},
+	{Vec3{1, 2, 3}, Vec3{-4, 0, 7}, Vec3{0, 1, 0}},
+	{Vec3{0, 0, 0}, Vec3{0, 5, 0}, Vec3{0, 1, 0}},
+}
+
+func TestBillboard(t *testing.T) {
+	for _, test := range billboardtests {
+		m := Billboard(&test.pos, &test.cam, &test.up)
+		f := m.TransfDir(&Vec3{0, 0, 1})
+		r := Subbed(&test.cam, &test.pos)
+		r.Norm()
+		if !f.ApproxEq(r, eps) {
+			t.Errorf("expected '%v' but got '%v'", *r, *f)
+		}
+		p := m.Transf(NewVec4(0, 0, 0)).ToVec3()
+		if !p.ApproxEq(&test.pos, eps) {
+			t.Errorf("expected '%v' but got '%v'", test.pos, *p)
+		}
+		if math.Abs(m.Det()-1) > eps {
+			t.Errorf("expected determinant '%v' but got '%v'", 1, m.Det())
+		}
+	}
+}
+
+func TestBillboardCylindrical(t *testing.T) {
+	pos := Vec3{1, 2, 3}
+	cam := Vec3{1, 10, 8}
+	up := Vec3{0, 2, 0}
+	m := BillboardCylindrical(&pos, &cam, &up)
+	if y := m.TransfDir(&Vec3{0, 1, 0}); !y.ApproxEq(&Vec3{0, 1, 0}, eps) {
+		t.Errorf("expected '%v' but got '%v'", Vec3{0, 1, 0}, *y)
+	}
+	if z := m.TransfDir(&Vec3{0, 0, 1}); !z.ApproxEq(&Vec3{0, 0, 1}, eps) {
+		t.Errorf("expected '%v' but got '%v'", Vec3{0, 0, 1}, *z)
+	}
+	if math.Abs(m.Det()-1) > eps {
+		t.Errorf("expected determinant '%v' but got '%v'", 1, m.Det())
+	}
+}
+
+var orthobasistests = []Vec3{
+	{0, 0, -1},
+	{0, 1, 0},
+	{0, -3, 0},
+	{1e-9, 1, -1e-9},
+	{1, 2, 3},
+	{-5, 0.1, 0.2},
+}
+
+func TestOrthoBasis(t *testing.T) {
+	for _, f := range orthobasistests {
+		r, u := OrthoBasis(&f)
+		if l := r.Len(); math.Abs(l-1) > eps {
+			t.Errorf("%v: expected right length '%v' but got '%v'", f, 1, l)
+		}
+		if l := u.Len(); math.Abs(l-1) > eps {
+			t.Errorf("%v: expected up length '%v' but got '%v'", f, 1, l)
+		}
+		if d := Dot(r, u); math.Abs(d) > eps {
+			t.Errorf("%v: expected dot product '%v' but got '%v'", f, 0, d)
+		}
+		if d := Dot(r, &f); math.Abs(d) > eps {
+			t.Errorf("%v: expected dot product '%v' but got '%v'", f, 0, d)
+		}
+		if d := Dot(u, &f); math.Abs(d) > eps {
+			t.Errorf("%v: expected dot product '%v' but got '%v'", f, 0, d)
+		}
+		b := *Cross(r, u)
+		b.Neg()
+		n := f
+		n.Norm()
+		if !b.ApproxEq(&n, eps) {
+			t.Errorf("expected '%v' but got '%v'", n, b)
+		}
+	}
+}
+
+func TestRowCol(t *testing.T) {
+	m := Mat4{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	if r := m.Row(2); r != (Vec4{8, 9, 10, 11}) {
+		t.Errorf("expected '%v' but got '%v'", Vec4{8, 9, 10, 11}, r)
+	}
+	if c := m.Col(1); c != (Vec4{1, 5, 9, 13}) {
+		t.Errorf("expected '%v' but got '%v'", Vec4{1, 5, 9, 13}, c)
+	}
+}
+
+func TestAt(t *testing.T) {
+	m := Mat4{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	if a := m.At(1, 3); a != m[7] {
+		t.Errorf("expected '%v' but got '%v'", m[7], a)
+	}
+	if a := m.At(3, 0); a != m[12] {
+		t.Errorf("expected '%v' but got '%v'", m[12], a)
+	}
+	m.SetAt(2, 1, -1)
+	if m[9] != -1 {
+		t.Errorf("expected '%v' but got '%v'", -1, m[9])
+	}
+}
+
+func TestAtOutOfRange(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("expected panic for index out of range")
+		}
+	}()
+	m := IdentityMat()
+	m.At(0, 4)
+}
+
+func TestAddMat(t *testing.T) {
+	m := Mat4{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	m.AddMat(IdentityMat())
+	r := Mat4{1, 1, 2, 3, 4, 6, 6, 7, 8, 9, 11, 11, 12, 13, 14, 16}
+	if m != r {
+		t.Errorf("expected '%v' but got '%v'", r, m)
+	}
+}
+
+func TestScaleBy(t *testing.T) {
+	m := Mat4{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	m.ScaleBy(0.5)
+	r := Mat4{0, 0.5, 1, 1.5, 2, 2.5, 3, 3.5, 4, 4.5, 5, 5.5, 6, 6.5, 7, 7.5}
+	if m != r {
+		t.Errorf("expected '%v' but got '%v'", r, m)
+	}
+}
+
+func TestTrace(t *testing.T) {
+	if tr := IdentityMat().Trace(); tr != 4 {
+		t.Errorf("expected '%v' but got '%v'", 4, tr)
+	}
+	m := Mat4{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	if tr := m.Trace(); tr != 30 {
+		t.Errorf("expected '%v' but got '%v'", 30, tr)
+	}
+}
+
+func TestMul(t *testing.T) {
+	m := Mat4{0, 3, 0, 1, 6, 3, 5, 3, 7, 4, 8, 7, 3, 6, 0, 3}
+	n := Mat4{9, 0, 4, 10, 4, 7, 0, 5, 6, 5, 8, 7, 9, 10, 7, 10}
+	r := Mat4{21, 31, 7, 25, 123, 76, 85, 140, 190, 138, 141, 216, 78, 72, 33, 90}
+	m.Mul(&n)
+	if m != r {
+		t.Errorf("expected '%v' but got '%v'", r, m)
+	}
+}
+
+var multests = []struct {
+	m, n, r Mat4
+}{
+	{
+		Mat4{1, 2, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1},
+		*TranslationMat(3, 4, 5),
+		Mat4{1, 2, 0, 11, 0, 1, 0, 4, 0, 0, 1, 5, 0, 0, 0, 1},
+	},
+	{
+		*TranslationMat(3, 4, 5),
+		Mat4{1, 2, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1},
+		Mat4{1, 2, 0, 3, 0, 1, 0, 4, 0, 0, 1, 5, 0, 0, 0, 1},
+	},
+	{
+		Mat4{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		Mat4{1, 0, 0, 0, 0, 0, 1, 0, 0, 1, 0, 0, 0, 0, 0, 1},
+		Mat4{1, 3, 2, 4, 5, 7, 6, 8, 9, 11, 10, 12, 13, 15, 14, 16},
+	},
+	{
+		Mat4{1, 0, 0, 0, 0, 0, 1, 0, 0, 1, 0, 0, 0, 0, 0, 1},
+		Mat4{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		Mat4{1, 2, 3, 4, 9, 10, 11, 12, 5, 6, 7, 8, 13, 14, 15, 16},
+	},
+}
+
+func TestMulOrder(t *testing.T) {
+	for _, test := range multests {
+		m := test.m
+		m.Mul(&test.n)
+		if m != test.r {
+			t.Errorf("expected '%v' but got '%v'", test.r, m)
+		}
+	}
+}
+
+func TestMulSelf(t *testing.T) {
+	m := Mat4{0, 3, 0, 1, 6, 3, 5, 3, 7, 4, 8, 7, 3, 6, 0, 3}
+	r := *mulNaive(&m, &m)
+	m.Mul(&m)
+	if m != r {
+		t.Errorf("expected '%v' but got '%v'", r, m)
+	}
+}
+
+func TestMulTransf(t *testing.T) {
+	m := TranslationMat(1, 0, 0)
+	m.Mul(ScaleMat(2, 2, 2))
+	p := *m.Transf(NewVec4(1, 1, 1))
+	r := Vec4{3, 2, 2, 1}
+	if p != r {
+		t.Errorf("expected '%v' but got '%v'", r, p)
+	}
+}
+
+func TestMulFunc(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	a := RandMat(r)
+	b := RandMat(r)
+	c := RandMat(r)
+	ac, bc, cc := *a, *b, *c
+	p := Mul(a, Mul(b, c))
+	q := *a
+	q.Mul(b)
+	q.Mul(c)
+	if !p.ApproxEq(&q, eps) {
+		t.Errorf("expected '%v' but got '%v'", q, *p)
+	}
+	if *a != ac || *b != bc || *c != cc {
+		t.Errorf("expected arguments to be unchanged")
+	}
+}
+
+func TestMat4ApproxEq(t *testing.T) {
+	m := *IdentityMat()
+	for _, test := range approxeqtests {
+		n := m
+		n[14] += test.d
+		eq := m.ApproxEq(&n, test.eps)
+		if eq != test.eq {
+			t.Errorf("%v,%v: expected '%v' but got '%v'", n, test.eps, test.eq, eq)
+		}
+	}
+}
+
+func TestMat4IsFinite(t *testing.T) {
+	for _, test := range isfinitetests {
+		m := *IdentityMat()
+		m[7] = test.c
+		if f := m.IsFinite(); f != test.finite {
+			t.Errorf("%v: expected '%v' but got '%v'", m, test.finite, f)
+		}
+	}
+}
+
+func TestTransf(t *testing.T) {
+	m := Mat4{1, 3, 2, 2, 9, 10, 1, 9, 0, 4, 5, 1, 6, 8, 5, 8}
+	v := Vec4{10, 7, 0, 8}
+	r := Vec4{47, 232, 36, 180}
+	p := *m.Transf(&v)
+	if p != r {
+		t.Errorf("expected '%v' but got '%v'", r, p)
+	}
+}
+
+func TestTranspose(t *testing.T) {
+	m := Mat4{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	n := m
+	n.Transpose()
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			if n[i*4+j] != m[j*4+i] {
+				t.Errorf("expected '%v' at (%v,%v) but got '%v'", m[j*4+i], i, j, n[i*4+j])
+			}
+		}
+	}
+	n.Transpose()
+	if n != m {
+		t.Errorf("expected '%v' but got '%v'", m, n)
+	}
+}
+
+func TestTransposed(t *testing.T) {
+	m := Mat4{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	c := m
+	n := *Transposed(Transposed(&m))
+	if n != m {
+		t.Errorf("expected '%v' but got '%v'", m, n)
+	}
+	if m != c {
+		t.Errorf("expected '%v' to be unchanged but got '%v'", c, m)
+	}
+}
+
+var dettests = []struct {
+	m   Mat4
+	det float64
+}{
+	{*IdentityMat(), 1},
+	{Mat4{1, 2, 3, 4, 0, 0, 0, 0, 5, 6, 7, 8, 9, 10, 11, 12}, 0},
+	{*ScaleMat(2, 3, 4), 24},
+	{Mat4{0, 3, 0, 1, 6, 3, 5, 3, 7, 4, 8, 7, 3, 6, 0, 3}, -150},
+}
+
+func TestDet(t *testing.T) {
+	for _, test := range dettests {
+		d := test.m.Det()
+		if d != test.det {
+			t.Errorf("expected '%v' but got '%v'", test.det, d)
+		}
+	}
+}
+
+func TestInverse(t *testing.T) {
+	m := TranslationMat(1, -2, 3)
+	m.Mul(RotAxisMat(&Vec3{1, 1, 0}, 0.7))
+	m.Mul(ScaleMat(2, 0.5, 3))
+	n, ok := m.Inverse()
+	if !ok {
+		t.Fatalf("expected '%v' but got '%v'", true, ok)
+	}
+	p := *m
+	p.Mul(n)
+	if !p.ApproxEq(IdentityMat(), eps) {
+		t.Errorf("expected '%v' but got '%v'", *IdentityMat(), p)
+	}
+	n.Mul(m)
+	if !n.ApproxEq(IdentityMat(), eps) {
+		t.Errorf("expected '%v' but got '%v'", *IdentityMat(), *n)
+	}
+}
+
+func TestInverseSmallScale(t *testing.T) {
+	m := ScaleMat(1e-4, 1e-4, 1e-4)
+	m.Mul(RotAxisMat(&Vec3{1, 2, 3}, 0.5))
+	n, ok := m.Inverse()
+	if !ok {
+		t.Fatalf("expected '%v' but got '%v'", true, ok)
+	}
+	n.Mul(m)
+	if !n.ApproxEq(IdentityMat(), eps) {
+		t.Errorf("expected '%v' but got '%v'", *IdentityMat(), *n)
+	}
+	if n := ScaleMat(1e-4, 1e-4, 1e-4).NormalMatrix(); n == nil {
+		t.Errorf("expected normal matrix but got '%v'", n)
+	}
+}
+
+func TestInverseNearSingular(t *testing.T) {
+	// Rows that are almost parallel.
+	m := Mat4{1, 2, 3, 0, 1, 2 + 1e-14, 3, 0, 0, 0, 1, 0, 0, 0, 0, 1}
+	if n, ok := m.Inverse(); ok || n != nil {
+		t.Errorf("expected '%v' but got '%v'", false, ok)
+	}
+	// The determinant is representable but its reciprocal is not.
+	m = *ScaleMat(1, 1, 1e-300)
+	m[15] = 1e-10
+	if n, ok := m.Inverse(); ok || n != nil {
+		t.Errorf("expected '%v' but got '%v'", false, ok)
+	}
+}
+
+func TestInverseSingular(t *testing.T) {
+	m := Mat4{1, 2, 3, 4, 0, 0, 0, 0, 5, 6, 7, 8, 9, 10, 11, 12}
+	n, ok := m.Inverse()
+	if ok {
+		t.Errorf("expected '%v' but got '%v'", false, ok)
+	}
+	if n != nil {
+		t.Errorf("expected '%v' but got '%v'", nil, n)
+	}
+}
+
+func TestInverseEps(t *testing.T) {
+	m := ScaleMat(1e-3, 1e-3, 0.9e-3)
+	if n, ok := m.InverseEps(1e-9); ok || n != nil {
+		t.Errorf("expected '%v' but got '%v'", false, ok)
+	}
+	n, ok := m.InverseEps(0.8e-9)
+	if !ok {
+		t.Fatalf("expected '%v' but got '%v'", true, ok)
+	}
+	if r := ScaleMat(1e3, 1e3, 1e3/0.9); !n.ApproxEq(r, 1e-9) {
+		t.Errorf("expected '%v' but got '%v'", *r, *n)
+	}
+	if _, ok := ScaleMat(1e-4, 1e-4, 1e-4).InverseEps(1e-12); ok {
+		t.Errorf("expected '%v' but got '%v'", false, ok)
+	}
+}
+
+func TestPow(t *testing.T) {
+	m := TranslationMat(1, -2, 3)
+	m.Mul(RotAxisMat(&Vec3{1, 1, 0}, 0.7))
+	m.Mul(ScaleMat(2, 0.5, 3))
+	if p := m.Pow(0); !p.ApproxEq(IdentityMat(), 0) {
+		t.Errorf("expected '%v' but got '%v'", *IdentityMat(), *p)
+	}
+	if p := m.Pow(1); !p.ApproxEq(m, 0) {
+		t.Errorf("expected '%v' but got '%v'", *m, *p)
+	}
+	r := Mul(m, m)
+	if p := m.Pow(2); !p.ApproxEq(r, eps) {
+		t.Errorf("expected '%v' but got '%v'", *r, *p)
+	}
+	r = Mul(r, m)
+	r.Mul(r)
+	r.Mul(m)
+	if p := m.Pow(7); !p.ApproxEq(r, 1e-6) {
+		t.Errorf("expected '%v' but got '%v'", *r, *p)
+	}
+	i, _ := m.Inverse()
+	if p := m.Pow(-1); !p.ApproxEq(i, eps) {
+		t.Errorf("expected '%v' but got '%v'", *i, *p)
+	}
+	i.Mul(i)
+	if p := m.Pow(-2); !p.ApproxEq(i, eps) {
+		t.Errorf("expected '%v' but got '%v'", *i, *p)
+	}
+}
+
+func TestPowSingular(t *testing.T) {
+	m := Mat4{1, 2, 3, 4, 0, 0, 0, 0, 5, 6, 7, 8, 9, 10, 11, 12}
+	if p := m.Pow(-1); p != nil {
+		t.Errorf("expected '%v' but got '%v'", nil, p)
+	}
+	if p := m.Pow(2); p == nil {
+		t.Errorf("expected matrix but got '%v'", p)
+	}
+}
+
+var decomposetests = []struct {
+	t, axis, s Vec3
+	rad        float64
+}{
+	{Vec3{1, -2, 3}, Vec3{1, 1, 0}, Vec3{2, 0.5, 3}, 0.7},
+	{Vec3{0, 0, 0}, Vec3{0, 0, 1}, Vec3{1, 1, 1}, 0},
+	{Vec3{5, 6, 7}, Vec3{0, 1, 0}, Vec3{-2, 1, 4}, -1.3},
+}
+
+func TestDecompose(t *testing.T) {
+	for _, test := range decomposetests {
+		m := TranslationMat(test.t[0], test.t[1], test.t[2])
+		m.Mul(RotAxisMat(&test.axis, test.rad))
+		m.Mul(ScaleMat(test.s[0], test.s[1], test.s[2]))
+		tr, rot, sc := m.Decompose()
+		if math.Abs(rot.Det()-1) > eps {
+			t.Errorf("expected rotation determinant '%v' but got '%v'", 1, rot.Det())
+		}
+		n := TranslationMat(tr[0], tr[1], tr[2])
+		n.Mul(rot)
+		n.Mul(ScaleMat(sc[0], sc[1], sc[2]))
+		if !n.ApproxEq(m, eps) {
+			t.Errorf("expected '%v' but got '%v'", *m, *n)
+		}
+	}
+}
+
+// mulNaive is the straightforward matrix multiplication used as reference for
+// Mat4.Mul.
+func mulNaive(m, n *Mat4) *Mat4 {
+	t := ZeroMat()
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			for k := 0; k < 4; k++ {
+				t[i*4+j] += m[i*4+k] * n[j+k*4]
+			}
+		}
+	}
+	return t
+}
+
+func TestMulNaive(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	for i := 0; i < 100; i++ {
+		m := RandMat(r)
+		n := RandMat(r)
+		p := mulNaive(m, n)
+		m.Mul(n)
+		if *m != *p {
+			t.Errorf("expected '%v' but got '%v'", *p, *m)
+		}
+	}
+}
+
+func BenchmarkMulNaive(b *testing.B) {
+	r := rand.New(rand.NewSource(0))
+	m := RandMat(r)
+	n := RandMat(r)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		*m = *mulNaive(m, n)
+	}
+}
+
+func BenchmarkMul(b *testing.B) {
+	r := rand.New(rand.NewSource(0))
+	m := RandMat(r)
+	n := RandMat(r)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Mul(n)
+	}
+}
+
+// randVec4s returns n vectors with random components.
+func randVec4s(r *rand.Rand, n int) []Vec4 {
+	p := make([]Vec4, n)
+	for i := range p {
+		p[i] = Vec4{r.Float64(), r.Float64(), r.Float64(), r.Float64()}
+	}
+	return p
+}
+
+func TestTransfDir(t *testing.T) {
+	m := Mul(TranslationMat(10, 20, 30), RotZMat(math.Pi/2))
+	m.Mul(ScaleMat(2, 2, 2))
+	d := m.TransfDir(&Vec3{1, 0, 0})
+	r := Vec3{0, 2, 0}
+	if !d.ApproxEq(&r, eps) {
+		t.Errorf("expected '%v' but got '%v'", r, *d)
+	}
+	n := TranslationMat(10, 20, 30).TransfDir(&Vec3{1, 2, 3})
+	if *n != (Vec3{1, 2, 3}) {
+		t.Errorf("expected '%v' but got '%v'", Vec3{1, 2, 3}, *n)
+	}
+}
+
+func TestTransfAll(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	m := RandMat(r)
+	p := randVec4s(r, 100)
+	q := make([]Vec4, len(p))
+	for i := range p {
+		q[i] = *m.Transf(&p[i])
+	}
+	m.TransfAll(p)
+	for i := range p {
+		if p[i] != q[i] {
+			t.Errorf("expected '%v' but got '%v'", q[i], p[i])
+		}
+	}
+}
+
+func BenchmarkTransf(b *testing.B) {
+	r := rand.New(rand.NewSource(0))
+	m := RandMat(r)
+	p := randVec4s(r, 1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range p {
+			p[j] = *m.Transf(&p[j])
+		}
+	}
+}
+
+func BenchmarkTransfAll(b *testing.B) {
+	r := rand.New(rand.NewSource(0))
+	m := RandMat(r)
+	p := randVec4s(r, 1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.TransfAll(p)
+	}
+}
+
+func TestNormalMatrixRotation(t *testing.T) {
+	r := RotAxisMat(&Vec3{1, 2, 3}, 0.8)
+	m := Mul(TranslationMat(4, 5, 6), r)
+	n := m.NormalMatrix()
+	if !n.ApproxEq(r, eps) {
+		t.Errorf("expected '%v' but got '%v'", *r, *n)
+	}
+}
+
+func TestNormalMatrixScale(t *testing.T) {
+	m := ScaleMat(2, 1, 1)
+	n := m.NormalMatrix()
+	r := ScaleMat(0.5, 1, 1)
+	if !n.ApproxEq(r, eps) {
+		t.Errorf("expected '%v' but got '%v'", *r, *n)
+	}
+	// The normal of the plane x = y stays perpendicular to the scaled plane.
+	p := m.Transf(&Vec4{1, 1, 0, 0})
+	q := n.Transf(&Vec4{1, -1, 0, 0})
+	if d := p[0]*q[0] + p[1]*q[1] + p[2]*q[2]; math.Abs(d) > eps {
+		t.Errorf("expected dot product '%v' but got '%v'", 0, d)
+	}
+}
+
+func TestNormalMatrixSingular(t *testing.T) {
+	if n := ScaleMat(1, 0, 1).NormalMatrix(); n != nil {
+		t.Errorf("expected '%v' but got '%v'", nil, *n)
+	}
+}
+
+func TestOrthonormalize(t *testing.T) {
+	r := rand.New(rand.NewSource(0))
+	m := Mul(TranslationMat(1, 2, 3), RotAxisMat(&Vec3{1, 2, 3}, 0.9))
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			m[i*4+j] += (r.Float64() - 0.5) * 0.01
+		}
+	}
+	m.Orthonormalize()
+	for i := 0; i < 3; i++ {
+		c := m.Col(i)
+		for j := i; j < 3; j++ {
+			d := m.Col(j)
+			dot := c[0]*d[0] + c[1]*d[1] + c[2]*d[2]
+			r := 0.0
+			if i == j {
+				r = 1
+			}
+			if math.Abs(dot-r) > eps {
+				t.Errorf("expected column %v·%v '%v' but got '%v'", i, j, r, dot)
+			}
+		}
+	}
+	if c := m.Col(3); c != (Vec4{1, 2, 3, 1}) {
+		t.Errorf("expected '%v' but got '%v'", Vec4{1, 2, 3, 1}, c)
+	}
+	if d := m.Det(); math.Abs(d-1) > eps {
+		t.Errorf("expected determinant '%v' but got '%v'", 1, d)
+	}
+}
+
+func TestCovariance(t *testing.T) {
+	points := []Vec3{{1, 2, 3}, {3, 2, 1}, {2, 5, 2}, {2, -1, 2}}
+	c := Covariance(points)
+	r := Mat4{
+		0.5, 0, -0.5, 0,
+		0, 4.5, 0, 0,
+		-0.5, 0, 0.5, 0,
+		0, 0, 0, 1,
+	}
+	if !c.ApproxEq(&r, eps) {
+		t.Errorf("expected '%v' but got '%v'", r, *c)
+	}
+	if c := Covariance(nil); c != nil {
+		t.Errorf("expected '%v' but got '%v'", nil, c)
+	}
+}
+
+func TestDominantEigenvector(t *testing.T) {
+	points := []Vec3{{-4, 0.1, 0}, {-1, -0.2, 0.1}, {0, 0, -0.1}, {2, 0.1, 0}, {5, 0, 0.1}}
+	e := DominantEigenvector(Covariance(points))
+	if a := math.Abs(Dot(e, &Vec3{1, 0, 0})); a < 0.999 {
+		t.Errorf("expected '%v' but got '%v'", Vec3{1, 0, 0}, *e)
+	}
+	points = []Vec3{{1, 1, 1}, {2, 2, 2}, {3, 3, 3}}
+	e = DominantEigenvector(Covariance(points))
+	r := Vec3{1, 1, 1}
+	r.Norm()
+	if a := math.Abs(Dot(e, &r)); math.Abs(a-1) > eps {
+		t.Errorf("expected '%v' but got '%v'", r, *e)
+	}
+	points = []Vec3{{1, 2, 3}, {1, 2, 3}}
+	if e := DominantEigenvector(Covariance(points)); e != nil {
+		t.Errorf("expected '%v' but got '%v'", nil, e)
 	}
 }
@@ -0,0 +1,84 @@
+package geom
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// jsonVec is the JSON representation of vectors, with named components.
+type jsonVec struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+	W float64 `json:"w"`
+}
+
+// jsonVec3 is the JSON representation of Vec3, without w.
+type jsonVec3 struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
+}
+
+// MarshalJSON encodes the vector as an object {"x":..,"y":..,"z":..}.
+func (v Vec3) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonVec3{v[0], v[1], v[2]})
+}
+
+// UnmarshalJSON decodes the vector from an object {"x":..,"y":..,"z":..}.
+// Missing components are set to 0.
+func (v *Vec3) UnmarshalJSON(data []byte) error {
+	var j jsonVec3
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*v = Vec3{j.X, j.Y, j.Z}
+	return nil
+}
+
+// MarshalJSON encodes the vector as an object {"x":..,"y":..,"z":..,"w":..}.
+func (v Vec4) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonVec{v[0], v[1], v[2], v[3]})
+}
+
+// UnmarshalJSON decodes the vector from an object
+// {"x":..,"y":..,"z":..,"w":..}. Missing components are set to 0.
+func (v *Vec4) UnmarshalJSON(data []byte) error {
+	var j jsonVec
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*v = Vec4{j.X, j.Y, j.Z, j.W}
+	return nil
+}
+
+// MarshalJSON encodes the matrix as an array of 4 rows, each an array of 4
+// components.
+func (m Mat4) MarshalJSON() ([]byte, error) {
+	var rows [4][4]float64
+	for i := range m {
+		rows[i/4][i%4] = m[i]
+	}
+	return json.Marshal(rows)
+}
+
+// UnmarshalJSON decodes the matrix from an array of 4 rows, each an array of
+// 4 components. Any other layout results in an error.
+func (m *Mat4) UnmarshalJSON(data []byte) error {
+	var rows [][]float64
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return err
+	}
+	if len(rows) != 4 {
+		return errors.New("Matrix must have 4 rows")
+	}
+	for i := range rows {
+		if len(rows[i]) != 4 {
+			return errors.New("Matrix rows must have 4 components")
+		}
+	}
+	for i := range m {
+		m[i] = rows[i/4][i%4]
+	}
+	return nil
+}
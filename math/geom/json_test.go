@@ -0,0 +1,72 @@
+package geom
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestVec3JSON(t *testing.T) {
+	v := Vec3{1, -2.5, 1e-3}
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := `{"x":1,"y":-2.5,"z":0.001}`
+	if string(b) != s {
+		t.Errorf("expected '%v' but got '%v'", s, string(b))
+	}
+	var w Vec3
+	if err := json.Unmarshal(b, &w); err != nil {
+		t.Fatal(err)
+	}
+	if w != v {
+		t.Errorf("expected '%v' but got '%v'", v, w)
+	}
+}
+
+func TestVec4JSON(t *testing.T) {
+	v := Vec4{1, 2, 3, 0.5}
+	b, err := json.Marshal(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := `{"x":1,"y":2,"z":3,"w":0.5}`
+	if string(b) != s {
+		t.Errorf("expected '%v' but got '%v'", s, string(b))
+	}
+	var w Vec4
+	if err := json.Unmarshal(b, &w); err != nil {
+		t.Fatal(err)
+	}
+	if w != v {
+		t.Errorf("expected '%v' but got '%v'", v, w)
+	}
+}
+
+func TestMat4JSON(t *testing.T) {
+	m := Mat4{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15.5}
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := `[[0,1,2,3],[4,5,6,7],[8,9,10,11],[12,13,14,15.5]]`
+	if string(b) != s {
+		t.Errorf("expected '%v' but got '%v'", s, string(b))
+	}
+	var n Mat4
+	if err := json.Unmarshal(b, &n); err != nil {
+		t.Fatal(err)
+	}
+	if n != m {
+		t.Errorf("expected '%v' but got '%v'", m, n)
+	}
+}
+
+func TestMat4JSONInvalid(t *testing.T) {
+	for _, s := range []string{`[[1,2,3,4]]`, `[[1],[2],[3],[4]]`, `{"x":1}`} {
+		var m Mat4
+		if err := json.Unmarshal([]byte(s), &m); err == nil {
+			t.Errorf("%v: expected error but got '%v'", s, err)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package geom
+
+// Plane is an infinite plane in 3D space, given by the points p for which
+// Normal·p + D = 0. The normal is expected to be of length 1, so Distance
+// returns actual distances.
+type Plane struct {
+
+	// Normal is the unit vector perpendicular to the plane. It points to the
+	// positive side of the plane.
+	Normal Vec3
+
+	// D is the negative distance of the plane from the origin along Normal.
+	D float64
+}
+
+// PlaneFromPointNormal returns a new plane through point p perpendicular to
+// normal n. The normal is normalized first, it must not be of length 0.
+func PlaneFromPointNormal(p, n *Vec3) *Plane {
+	u, _ := n.Normalized()
+	return &Plane{u, -Dot(&u, p)}
+}
+
+// Distance returns the signed distance of a point from the plane. It is
+// positive on the side the normal points to, negative on the other side and 0
+// on the plane.
+func (pl *Plane) Distance(p *Vec3) float64 {
+	return Dot(&pl.Normal, p) + pl.D
+}
+
+// ReflectionMat returns a new matrix that mirrors points across the plane.
+// The plane is normalized first, so its normal does not need to be of length
+// 1. A plane with a normal of length 0 results in the identity matrix.
+func ReflectionMat(pl *Plane) *Mat4 {
+	l := pl.Normal.Len()
+	if l == 0 {
+		return IdentityMat()
+	}
+	a, b, c := pl.Normal[0]/l, pl.Normal[1]/l, pl.Normal[2]/l
+	d := pl.D / l
+	return &Mat4{
+		1 - 2*a*a, -2 * a * b, -2 * a * c, -2 * a * d,
+		-2 * a * b, 1 - 2*b*b, -2 * b * c, -2 * b * d,
+		-2 * a * c, -2 * b * c, 1 - 2*c*c, -2 * c * d,
+		0, 0, 0, 1,
+	}
+}
+
+// Transform returns a new plane that contains the points of the plane
+// transformed by the matrix m. Plane coefficients transform with the inverse
+// transpose of m, so this also works for non-uniform scaling where the normal
+// would not stay perpendicular otherwise. The result is normalized. Returns
+// nil if m is not invertible.
+func (pl *Plane) Transform(m *Mat4) *Plane {
+	i, ok := m.Inverse()
+	if !ok {
+		return nil
+	}
+	i.Transpose()
+	p := i.Transf(&Vec4{pl.Normal[0], pl.Normal[1], pl.Normal[2], pl.D})
+	n := Vec3{p[0], p[1], p[2]}
+	l := n.Len()
+	if l == 0 {
+		return nil
+	}
+	n.Scale(1 / l)
+	return &Plane{n, p[3] / l}
+}
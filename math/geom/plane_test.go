@@ -0,0 +1,86 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+var distancetests = []struct {
+	p    Vec3
+	dist float64
+}{
+	{Vec3{1, 2, 3}, 2},
+	{Vec3{-4, -0.5, 7}, -0.5},
+	{Vec3{5, 0, -5}, 0},
+}
+
+func TestPlaneDistance(t *testing.T) {
+	pl := PlaneFromPointNormal(&Vec3{3, 0, 1}, &Vec3{0, 5, 0})
+	for _, test := range distancetests {
+		d := pl.Distance(&test.p)
+		if math.Abs(d-test.dist) > eps {
+			t.Errorf("%v: expected '%v' but got '%v'", test.p, test.dist, d)
+		}
+	}
+}
+
+func TestPlaneFromPointNormal(t *testing.T) {
+	pl := *PlaneFromPointNormal(&Vec3{0, 0, 2}, &Vec3{0, 0, -3})
+	r := Plane{Vec3{0, 0, -1}, 2}
+	if pl != r {
+		t.Errorf("expected '%v' but got '%v'", r, pl)
+	}
+}
+
+var reflectionmattests = []struct {
+	pl   Plane
+	p, r Vec3
+}{
+	{Plane{Vec3{0, 1, 0}, 0}, Vec3{1, 2, 3}, Vec3{1, -2, 3}},
+	{Plane{Vec3{0, 3, 0}, 0}, Vec3{1, -2, 3}, Vec3{1, 2, 3}},
+	{Plane{Vec3{0, 2, 0}, -2}, Vec3{1, 3, 3}, Vec3{1, -1, 3}},
+	{Plane{Vec3{1, 1, 0}, 0}, Vec3{1, 0, 5}, Vec3{0, -1, 5}},
+}
+
+func TestReflectionMat(t *testing.T) {
+	for _, test := range reflectionmattests {
+		m := ReflectionMat(&test.pl)
+		p := m.Transf(test.p.ToVec4()).ToVec3()
+		if !p.ApproxEq(&test.r, eps) {
+			t.Errorf("%v: expected '%v' but got '%v'", test.pl, test.r, *p)
+		}
+	}
+}
+
+func TestPlaneTransform(t *testing.T) {
+	pl := PlaneFromPointNormal(&Vec3{1, 2, 3}, &Vec3{1, 1, 0})
+	m := TranslationMat(4, -5, 6)
+	m.Mul(RotAxisMat(&Vec3{1, 2, 3}, 0.8))
+	m.Mul(ScaleMat(2, 0.5, 3))
+	tp := pl.Transform(m)
+	points := []Vec3{{1, 2, 3}, {0, 3, 0}, {2, 1, -7}}
+	for _, p := range points {
+		if d := pl.Distance(&p); math.Abs(d) > eps {
+			t.Fatalf("expected '%v' on original plane but got distance '%v'", p, d)
+		}
+		q := m.Transf(p.ToVec4()).ToVec3()
+		if d := tp.Distance(q); math.Abs(d) > eps {
+			t.Errorf("%v: expected distance '%v' but got '%v'", *q, 0, d)
+		}
+	}
+	if l := tp.Normal.Len(); math.Abs(l-1) > eps {
+		t.Errorf("expected length '%v' but got '%v'", 1, l)
+	}
+	q := Vec3{1, 2, 3}
+	q.Add(&pl.Normal)
+	if d := tp.Distance(m.Transf(q.ToVec4()).ToVec3()); d <= 0 {
+		t.Errorf("expected positive distance but got '%v'", d)
+	}
+}
+
+func TestPlaneTransformSingular(t *testing.T) {
+	pl := Plane{Vec3{0, 1, 0}, 0}
+	if tp := pl.Transform(ScaleMat(1, 0, 1)); tp != nil {
+		t.Errorf("expected '%v' but got '%v'", nil, *tp)
+	}
+}
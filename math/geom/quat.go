@@ -0,0 +1,139 @@
+package geom
+
+import (
+	"math"
+)
+
+// Quat is a quaternion used to represent rotations in 3D space. Holds 4
+// components: x, y, z and w in this order, where x, y and z make up the
+// vector part and w the scalar part.
+type Quat [4]float64
+
+// QuatFromAxisAngle returns a new unit quaternion that rotates by rad radians
+// around the given axis, following the same right-handed convention as
+// RotAxisMat. The axis is normalized first. An axis of length 0 results in
+// the identity rotation.
+func QuatFromAxisAngle(axis *Vec3, rad float64) *Quat {
+	a, ok := axis.Normalized()
+	if !ok {
+		return &Quat{0, 0, 0, 1}
+	}
+	s, c := math.Sincos(rad / 2)
+	return &Quat{a[0] * s, a[1] * s, a[2] * s, c}
+}
+
+// Mul multiplies the quaternion with another one (Hamilton product q·r),
+// modifying the former one. As with matrices the resulting rotation applies r
+// first and then q.
+func (q *Quat) Mul(r *Quat) {
+	*q = Quat{
+		q[3]*r[0] + q[0]*r[3] + q[1]*r[2] - q[2]*r[1],
+		q[3]*r[1] - q[0]*r[2] + q[1]*r[3] + q[2]*r[0],
+		q[3]*r[2] + q[0]*r[1] - q[1]*r[0] + q[2]*r[3],
+		q[3]*r[3] - q[0]*r[0] - q[1]*r[1] - q[2]*r[2],
+	}
+}
+
+// Normalize normalizes the quaternion to length 1. Only unit quaternions
+// represent rotations. A quaternion of length 0 is left unchanged.
+func (q *Quat) Normalize() {
+	abs := math.Sqrt(q[0]*q[0] + q[1]*q[1] + q[2]*q[2] + q[3]*q[3])
+	if abs != 0 {
+		q[0] /= abs
+		q[1] /= abs
+		q[2] /= abs
+		q[3] /= abs
+	}
+}
+
+// Rotate returns a new vector that is v rotated by the quaternion, which must
+// be of unit length. It is cheaper than building the rotation matrix with
+// ToMat4 when rotating only a few vectors. With u being the vector part of q
+// it computes
+//
+//	v + 2*w*(u × v) + 2*(u × (u × v))
+func (q *Quat) Rotate(v *Vec3) *Vec3 {
+	u := Vec3{q[0], q[1], q[2]}
+	c := Cross(&u, v)
+	d := Cross(&u, c)
+	c.Scale(2 * q[3])
+	d.Scale(2)
+	r := Added(v, c)
+	r.Add(d)
+	return r
+}
+
+// ToMat4 returns a new rotation matrix corresponding to the quaternion. The
+// quaternion must be of unit length.
+func (q *Quat) ToMat4() *Mat4 {
+	x, y, z, w := q[0], q[1], q[2], q[3]
+	return &Mat4{
+		1 - 2*(y*y+z*z), 2 * (x*y - z*w), 2 * (x*z + y*w), 0,
+		2 * (x*y + z*w), 1 - 2*(x*x+z*z), 2 * (y*z - x*w), 0,
+		2 * (x*z - y*w), 2 * (y*z + x*w), 1 - 2*(x*x+y*y), 0,
+		0, 0, 0, 1,
+	}
+}
+
+// Slerp returns a new unit quaternion that spherically interpolates between
+// the rotations a and b: a for t = 0 and b for t = 1, with constant angular
+// speed in between. Both quaternions must be of unit length.
+//
+// Since q and -q represent the same rotation, b is negated if needed so the
+// interpolation takes the shorter path. If a and b are very close the
+// normalized linear interpolation is used instead, avoiding the division by a
+// tiny sine.
+func Slerp(a, b *Quat, t float64) *Quat {
+	c := *b
+	d := a[0]*c[0] + a[1]*c[1] + a[2]*c[2] + a[3]*c[3]
+	if d < 0 {
+		d = -d
+		for i := range c {
+			c[i] = -c[i]
+		}
+	}
+	var s0, s1 float64
+	if d > 1-1e-6 {
+		s0 = 1 - t
+		s1 = t
+	} else {
+		o := math.Acos(d)
+		s := math.Sin(o)
+		s0 = math.Sin((1-t)*o) / s
+		s1 = math.Sin(t*o) / s
+	}
+	q := Quat{
+		s0*a[0] + s1*c[0],
+		s0*a[1] + s1*c[1],
+		s0*a[2] + s1*c[2],
+		s0*a[3] + s1*c[3],
+	}
+	q.Normalize()
+	return &q
+}
+
+// QuatFromMat4 returns a new unit quaternion with the same rotation as the
+// upper-left 3x3 part of the matrix, which must be a pure rotation. To stay
+// numerically stable the trace of the 3x3 part decides which component is
+// computed from the diagonal: the scalar part w if the trace is positive,
+// otherwise the vector component with the largest diagonal entry.
+func QuatFromMat4(m *Mat4) *Quat {
+	tr := m[0] + m[5] + m[10]
+	var q Quat
+	switch {
+	case tr > 0:
+		s := 2 * math.Sqrt(tr+1)
+		q = Quat{(m[9] - m[6]) / s, (m[2] - m[8]) / s, (m[4] - m[1]) / s, s / 4}
+	case m[0] > m[5] && m[0] > m[10]:
+		s := 2 * math.Sqrt(1+m[0]-m[5]-m[10])
+		q = Quat{s / 4, (m[1] + m[4]) / s, (m[2] + m[8]) / s, (m[9] - m[6]) / s}
+	case m[5] > m[10]:
+		s := 2 * math.Sqrt(1+m[5]-m[0]-m[10])
+		q = Quat{(m[1] + m[4]) / s, s / 4, (m[6] + m[9]) / s, (m[2] - m[8]) / s}
+	default:
+		s := 2 * math.Sqrt(1+m[10]-m[0]-m[5])
+		q = Quat{(m[2] + m[8]) / s, (m[6] + m[9]) / s, s / 4, (m[4] - m[1]) / s}
+	}
+	q.Normalize()
+	return &q
+}
@@ -0,0 +1,163 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+var quattests = []struct {
+	axis Vec3
+	rad  float64
+}{
+	{Vec3{1, 0, 0}, math.Pi / 2},
+	{Vec3{0, 1, 0}, -0.5},
+	{Vec3{0, 0, 3}, 2},
+	{Vec3{1, 2, 3}, 1.2},
+	{Vec3{-1, 1, 0}, math.Pi},
+}
+
+func TestQuatToMat4(t *testing.T) {
+	for _, test := range quattests {
+		m := QuatFromAxisAngle(&test.axis, test.rad).ToMat4()
+		r := RotAxisMat(&test.axis, test.rad)
+		if !m.ApproxEq(r, eps) {
+			t.Errorf("axis %v: expected '%v' but got '%v'", test.axis, *r, *m)
+		}
+	}
+}
+
+func TestQuatRotate(t *testing.T) {
+	v := Vec3{1, -2, 0.5}
+	for _, test := range quattests {
+		q := QuatFromAxisAngle(&test.axis, test.rad)
+		w := q.Rotate(&v)
+		r := q.ToMat4().TransfDir(&v)
+		if !w.ApproxEq(r, eps) {
+			t.Errorf("axis %v: expected '%v' but got '%v'", test.axis, *r, *w)
+		}
+	}
+	id := Quat{0, 0, 0, 1}
+	if w := *id.Rotate(&v); w != v {
+		t.Errorf("expected '%v' but got '%v'", v, w)
+	}
+}
+
+func TestQuatFromAxisAngleZero(t *testing.T) {
+	q := *QuatFromAxisAngle(&Vec3{0, 0, 0}, 1)
+	r := Quat{0, 0, 0, 1}
+	if q != r {
+		t.Errorf("expected '%v' but got '%v'", r, q)
+	}
+}
+
+func TestQuatMul(t *testing.T) {
+	a := Vec3{1, 2, 3}
+	b := Vec3{0, 1, -1}
+	q := QuatFromAxisAngle(&a, 0.7)
+	q.Mul(QuatFromAxisAngle(&b, -1.1))
+	m := Mul(RotAxisMat(&a, 0.7), RotAxisMat(&b, -1.1))
+	n := q.ToMat4()
+	if !n.ApproxEq(m, eps) {
+		t.Errorf("expected '%v' but got '%v'", *m, *n)
+	}
+}
+
+func TestQuatNormalize(t *testing.T) {
+	q := Quat{0, 3, 0, 4}
+	q.Normalize()
+	r := Quat{0, 0.6, 0, 0.8}
+	if q != r {
+		t.Errorf("expected '%v' but got '%v'", r, q)
+	}
+	z := Quat{0, 0, 0, 0}
+	z.Normalize()
+	if z != (Quat{0, 0, 0, 0}) {
+		t.Errorf("expected '%v' but got '%v'", Quat{0, 0, 0, 0}, z)
+	}
+}
+
+// quatApproxEq returns true if both quaternions represent the same rotation,
+// i.e. all components of q and either r or -r differ by at most e.
+func quatApproxEq(q, r *Quat, e float64) bool {
+	pos, neg := true, true
+	for i := range q {
+		if math.Abs(q[i]-r[i]) > e {
+			pos = false
+		}
+		if math.Abs(q[i]+r[i]) > e {
+			neg = false
+		}
+	}
+	return pos || neg
+}
+
+var slerptests = []struct {
+	t   float64
+	rad float64
+}{
+	{0, 0},
+	{1, 2},
+	{0.5, 1},
+	{0.25, 0.5},
+}
+
+func TestSlerp(t *testing.T) {
+	axis := Vec3{1, 1, 0}
+	a := QuatFromAxisAngle(&axis, 0)
+	b := QuatFromAxisAngle(&axis, 2)
+	for _, test := range slerptests {
+		q := Slerp(a, b, test.t)
+		r := QuatFromAxisAngle(&axis, test.rad)
+		if !quatApproxEq(q, r, eps) {
+			t.Errorf("t=%v: expected '%v' but got '%v'", test.t, *r, *q)
+		}
+	}
+}
+
+func TestSlerpShortPath(t *testing.T) {
+	axis := Vec3{0, 0, 1}
+	a := QuatFromAxisAngle(&axis, 0.2)
+	b := QuatFromAxisAngle(&axis, 0.6)
+	for i := range b {
+		b[i] = -b[i]
+	}
+	q := Slerp(a, b, 0.5)
+	r := QuatFromAxisAngle(&axis, 0.4)
+	if !quatApproxEq(q, r, eps) {
+		t.Errorf("expected '%v' but got '%v'", *r, *q)
+	}
+}
+
+func TestSlerpClose(t *testing.T) {
+	axis := Vec3{0, 1, 0}
+	a := QuatFromAxisAngle(&axis, 1)
+	b := QuatFromAxisAngle(&axis, 1+1e-9)
+	q := Slerp(a, b, 0.5)
+	for _, c := range q {
+		if math.IsNaN(c) {
+			t.Fatalf("expected no NaN but got '%v'", *q)
+		}
+	}
+	if !quatApproxEq(q, a, 1e-6) {
+		t.Errorf("expected '%v' but got '%v'", *a, *q)
+	}
+}
+
+func TestQuatFromMat4(t *testing.T) {
+	tests := append(quattests, []struct {
+		axis Vec3
+		rad  float64
+	}{
+		{Vec3{1, 0, 0}, 3},
+		{Vec3{0, 1, 0}, 3},
+		{Vec3{0, 0, 1}, 3},
+		{Vec3{0, 0, 1}, 0},
+	}...)
+	for _, test := range tests {
+		q := QuatFromAxisAngle(&test.axis, test.rad)
+		p := QuatFromMat4(q.ToMat4())
+		if !quatApproxEq(p, q, eps) {
+			t.Errorf("axis %v, %v: expected '%v' but got '%v'", test.axis, test.rad, *q, *p)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package geom
+
+import (
+	"math"
+)
+
+// Ray is a half-line starting at an origin and extending into a direction.
+// The points on the ray are Origin + t*Dir for t >= 0.
+type Ray struct {
+
+	// Origin is the point the ray starts from.
+	Origin Vec3
+
+	// Dir is the direction of the ray. It does not need to be of length 1,
+	// but distances along the ray are measured in multiples of its length.
+	Dir Vec3
+}
+
+// IntersectAABB returns the parameter t of the nearest point where the ray
+// hits the box, using the slab method. The returned bool is false if the ray
+// misses the box or the box is empty. If the origin lies inside the box t is
+// 0. Directions with components of 0 are handled explicitly, the ray then only
+// hits if the origin lies between the corresponding slab planes.
+func (r *Ray) IntersectAABB(b *AABB) (t float64, hit bool) {
+	if b.Empty() {
+		return 0, false
+	}
+	tmin := 0.0
+	tmax := math.Inf(1)
+	for i := 0; i < 3; i++ {
+		if r.Dir[i] == 0 {
+			if r.Origin[i] < b.Min[i] || r.Origin[i] > b.Max[i] {
+				return 0, false
+			}
+			continue
+		}
+		t1 := (b.Min[i] - r.Origin[i]) / r.Dir[i]
+		t2 := (b.Max[i] - r.Origin[i]) / r.Dir[i]
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		tmin = math.Max(tmin, t1)
+		tmax = math.Min(tmax, t2)
+		if tmin > tmax {
+			return 0, false
+		}
+	}
+	return tmin, true
+}
+
+// IntersectPlane returns the parameter t of the point where the ray hits the
+// plane. The returned bool is false if the ray is parallel to the plane or the
+// plane lies behind the origin.
+func (r *Ray) IntersectPlane(pl *Plane) (t float64, hit bool) {
+	d := Dot(&pl.Normal, &r.Dir)
+	if d == 0 {
+		return 0, false
+	}
+	t = -pl.Distance(&r.Origin) / d
+	if t < 0 {
+		return 0, false
+	}
+	return t, true
+}
+
+// IntersectTriangle returns the parameter t of the point where the ray hits
+// the triangle abc, using the Möller–Trumbore algorithm. The hit point is also
+// given in barycentric coordinates u and v, it equals (1-u-v)*a + u*b + v*c.
+// The returned bool is false if the ray misses the triangle, the triangle lies
+// behind the origin, the ray is parallel to the triangle or the triangle is
+// degenerate (has no area).
+//
+// Triangles are two-sided, both faces are hit. With counter-clockwise winding
+// of a, b and c as seen by the viewer the front face looks towards the viewer
+// and the ray hits it if its direction points against Cross(b-a, c-a).
+func (r *Ray) IntersectTriangle(a, b, c *Vec3) (t, u, v float64, hit bool) {
+	e1 := Subbed(b, a)
+	e2 := Subbed(c, a)
+	p := Cross(&r.Dir, e2)
+	det := Dot(e1, p)
+	if math.Abs(det) < 1e-12 {
+		return 0, 0, 0, false
+	}
+	inv := 1 / det
+	s := Subbed(&r.Origin, a)
+	u = Dot(s, p) * inv
+	if u < 0 || u > 1 {
+		return 0, 0, 0, false
+	}
+	q := Cross(s, e1)
+	v = Dot(&r.Dir, q) * inv
+	if v < 0 || u+v > 1 {
+		return 0, 0, 0, false
+	}
+	t = Dot(e2, q) * inv
+	if t < 0 {
+		return 0, 0, 0, false
+	}
+	return t, u, v, true
+}
+
+// Unproject returns a new ray in world coordinates from the near plane
+// through the given screen position, given the viewport size and the inverse
+// of the combined view and projection matrix. Screen coordinates have their
+// origin at the top left corner of the viewport with y pointing down, so y is
+// flipped when mapping to normalized device coordinates where y points up.
+// The ray starts on the near plane and its direction is normalized.
+func Unproject(screenX, screenY, viewportW, viewportH float64, invViewProj *Mat4) *Ray {
+	x := 2*screenX/viewportW - 1
+	y := 1 - 2*screenY/viewportH
+	n := invViewProj.Transf(&Vec4{x, y, -1, 1}).ToVec3()
+	f := invViewProj.Transf(&Vec4{x, y, 1, 1}).ToVec3()
+	d := Subbed(f, n)
+	d.Norm()
+	return &Ray{*n, *d}
+}
+
+// WorldToScreen returns the screen position of the point in world
+// coordinates, given the combined view and projection matrix and the viewport
+// size. It is the inverse of Unproject, with the origin at the top left corner
+// of the viewport and y pointing down. The returned bool is true if the point
+// is visible: in front of the camera (w > 0) and inside the cube [-1,1] in
+// normalized device coordinates. For points with w = 0 no screen position
+// exists and (0, 0, false) is returned.
+func WorldToScreen(world *Vec3, viewProj *Mat4, viewportW, viewportH float64) (sx, sy float64, visible bool) {
+	c := viewProj.Transf(world.ToVec4())
+	n := c.Perspective()
+	if n == nil {
+		return 0, 0, false
+	}
+	sx = (n[0] + 1) / 2 * viewportW
+	sy = (1 - n[1]) / 2 * viewportH
+	visible = c[3] > 0 &&
+		math.Abs(n[0]) <= 1 && math.Abs(n[1]) <= 1 && math.Abs(n[2]) <= 1
+	return sx, sy, visible
+}
@@ -0,0 +1,154 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+var intersectaabbtests = []struct {
+	b   AABB
+	r   Ray
+	t   float64
+	hit bool
+}{
+	{AABB{Vec3{0, 0, 0}, Vec3{1, 1, 1}}, Ray{Vec3{-5, 0.5, 0.5}, Vec3{1, 0, 0}}, 5, true},
+	{AABB{Vec3{0, 0, 0}, Vec3{1, 1, 1}}, Ray{Vec3{-5, -5, 0.5}, Vec3{1, 1, 0}}, 5, true},
+	{AABB{Vec3{0, 0, 0}, Vec3{1, 1, 1}}, Ray{Vec3{0.5, 0.5, 5}, Vec3{0, 0, -2}}, 2, true},
+	{AABB{Vec3{0, 0, 0}, Vec3{1, 1, 1}}, Ray{Vec3{0.5, 0.5, 0.5}, Vec3{0, 1, 0}}, 0, true},
+	{AABB{Vec3{0, 0, 0}, Vec3{1, 1, 1}}, Ray{Vec3{-5, 2, 0.5}, Vec3{1, 0, 0}}, 0, false},
+	{AABB{Vec3{0, 0, 0}, Vec3{1, 1, 1}}, Ray{Vec3{5, 0.5, 0.5}, Vec3{1, 0, 0}}, 0, false},
+	{AABB{Vec3{0, 0, 0}, Vec3{1, 1, 1}}, Ray{Vec3{-5, 0.5, 0.5}, Vec3{1, 1, 0}}, 0, false},
+	{AABB{Vec3{1, 0, 0}, Vec3{0, 1, 1}}, Ray{Vec3{-5, 0.5, 0.5}, Vec3{1, 0, 0}}, 0, false},
+}
+
+func TestIntersectAABB(t *testing.T) {
+	for _, test := range intersectaabbtests {
+		d, hit := test.r.IntersectAABB(&test.b)
+		if hit != test.hit {
+			t.Errorf("%v: expected hit '%v' but got '%v'", test.r, test.hit, hit)
+		}
+		if math.IsNaN(d) || math.Abs(d-test.t) > eps {
+			t.Errorf("%v: expected t '%v' but got '%v'", test.r, test.t, d)
+		}
+	}
+}
+
+var intersectplanetests = []struct {
+	r   Ray
+	t   float64
+	hit bool
+}{
+	{Ray{Vec3{1, 4, 2}, Vec3{0, -1, 0}}, 4, true},
+	{Ray{Vec3{1, 4, 2}, Vec3{0, -2, 0}}, 2, true},
+	{Ray{Vec3{0, -3, 0}, Vec3{1, 1, 0}}, 3, true},
+	{Ray{Vec3{1, 4, 2}, Vec3{1, 0, 0}}, 0, false},
+	{Ray{Vec3{1, 4, 2}, Vec3{0, 1, 0}}, 0, false},
+}
+
+func TestIntersectPlane(t *testing.T) {
+	pl := PlaneFromPointNormal(&Vec3{0, 0, 0}, &Vec3{0, 1, 0})
+	for _, test := range intersectplanetests {
+		d, hit := test.r.IntersectPlane(pl)
+		if hit != test.hit {
+			t.Errorf("%v: expected hit '%v' but got '%v'", test.r, test.hit, hit)
+		}
+		if math.Abs(d-test.t) > eps {
+			t.Errorf("%v: expected t '%v' but got '%v'", test.r, test.t, d)
+		}
+	}
+}
+
+func TestIntersectTriangleCentroid(t *testing.T) {
+	a := Vec3{0, 0, 0}
+	b := Vec3{3, 0, 0}
+	c := Vec3{0, 3, 0}
+	for _, z := range []float64{5, -5} {
+		r := Ray{Vec3{1, 1, z}, Vec3{0, 0, -z / 5}}
+		d, u, v, hit := r.IntersectTriangle(&a, &b, &c)
+		if !hit {
+			t.Fatalf("%v: expected hit '%v' but got '%v'", r, true, hit)
+		}
+		if math.Abs(d-5) > eps || math.Abs(u-1.0/3) > eps || math.Abs(v-1.0/3) > eps {
+			t.Errorf("%v: expected '%v' but got '%v'", r, []float64{5, 1.0 / 3, 1.0 / 3}, []float64{d, u, v})
+		}
+	}
+}
+
+var intersecttriangletests = []struct {
+	r    Ray
+	a, b Vec3
+	c    Vec3
+}{
+	{Ray{Vec3{4, 4, 5}, Vec3{0, 0, -1}}, Vec3{0, 0, 0}, Vec3{3, 0, 0}, Vec3{0, 3, 0}},
+	{Ray{Vec3{1, 1, -5}, Vec3{0, 0, -1}}, Vec3{0, 0, 0}, Vec3{3, 0, 0}, Vec3{0, 3, 0}},
+	{Ray{Vec3{1, 1, 5}, Vec3{1, 0, 0}}, Vec3{0, 0, 0}, Vec3{3, 0, 0}, Vec3{0, 3, 0}},
+	{Ray{Vec3{1, 1, 5}, Vec3{0, 0, -1}}, Vec3{0, 0, 0}, Vec3{1, 1, 0}, Vec3{2, 2, 0}},
+}
+
+func TestIntersectTriangleMiss(t *testing.T) {
+	for _, test := range intersecttriangletests {
+		_, _, _, hit := test.r.IntersectTriangle(&test.a, &test.b, &test.c)
+		if hit {
+			t.Errorf("%v: expected hit '%v' but got '%v'", test.r, false, hit)
+		}
+	}
+}
+
+func TestUnproject(t *testing.T) {
+	eye := Vec3{1, 2, 3}
+	center := Vec3{4, 2, -1}
+	m := PerspectiveMat(math.Pi/3, 4.0/3, 0.5, 50)
+	m.Mul(LookAt(&eye, &center, &Vec3{0, 1, 0}))
+	inv, _ := m.Inverse()
+	r := Unproject(400, 300, 800, 600, inv)
+	fwd := Subbed(&center, &eye)
+	fwd.Norm()
+	if !r.Dir.ApproxEq(fwd, 1e-6) {
+		t.Errorf("expected direction '%v' but got '%v'", *fwd, r.Dir)
+	}
+	o := Subbed(&r.Origin, &eye)
+	if d := Dist(o, Project(o, fwd)); d > 1e-6 {
+		t.Errorf("expected origin on the forward axis but got '%v'", r.Origin)
+	}
+	// A point in the upper half of the screen results in a ray pointing up.
+	u := Unproject(400, 100, 800, 600, inv)
+	if u.Dir[1] <= 0 {
+		t.Errorf("expected ray pointing up but got '%v'", u.Dir)
+	}
+}
+
+var worldtoscreentests = []struct {
+	p       Vec3
+	sx, sy  float64
+	visible bool
+}{
+	{Vec3{0, 0, -10}, 400, 300, true},
+	{Vec3{10, 0, -10}, 800, 300, true},
+	{Vec3{0, 10, -10}, 400, 0, true},
+	{Vec3{0, 0, 10}, 400, 300, false},
+	{Vec3{30, 0, -10}, 1600, 300, false},
+	{Vec3{0, 0, -200}, 400, 300, false},
+}
+
+func TestWorldToScreen(t *testing.T) {
+	m := PerspectiveMat(math.Pi/2, 1, 1, 100)
+	for _, test := range worldtoscreentests {
+		sx, sy, v := WorldToScreen(&test.p, m, 800, 600)
+		if math.Abs(sx-test.sx) > 1e-6 || math.Abs(sy-test.sy) > 1e-6 || v != test.visible {
+			t.Errorf("%v: expected '%v' but got '%v'", test.p,
+				[]interface{}{test.sx, test.sy, test.visible}, []interface{}{sx, sy, v})
+		}
+	}
+}
+
+func TestWorldToScreenUnproject(t *testing.T) {
+	m := PerspectiveMat(math.Pi/3, 4.0/3, 0.5, 50)
+	m.Mul(LookAt(&Vec3{1, 2, 3}, &Vec3{4, 2, -1}, &Vec3{0, 1, 0}))
+	inv, _ := m.Inverse()
+	r := Unproject(123, 456, 800, 600, inv)
+	p := Added(&r.Origin, &r.Dir)
+	sx, sy, v := WorldToScreen(p, m, 800, 600)
+	if math.Abs(sx-123) > 1e-6 || math.Abs(sy-456) > 1e-6 || !v {
+		t.Errorf("expected '%v' but got '%v'", []interface{}{123, 456, true}, []interface{}{sx, sy, v})
+	}
+}
@@ -0,0 +1,76 @@
+package geom
+
+import (
+	"math"
+)
+
+// ExpSO3 returns a new rotation matrix that is the matrix exponential of the
+// skew-symmetric matrix of omega: a rotation by |omega| radians around the
+// axis omega/|omega|. This is how an angular velocity omega integrated over a
+// unit time step becomes a rotation. It is computed as
+//
+//	R = I + a*[ω]× + b*[ω]×², a = sin(θ)/θ, b = (1-cos(θ))/θ², θ = |ω|
+//
+// For very small angles a and b are taken from their Taylor series instead, so
+// there is no division by a tiny magnitude and a zero omega results in the
+// identity.
+func ExpSO3(omega *Vec3) *Mat4 {
+	t2 := omega.LenSq()
+	var a, b float64
+	if t2 < 1e-8 {
+		a = 1 - t2/6 + t2*t2/120
+		b = 0.5 - t2/24 + t2*t2/720
+	} else {
+		t := math.Sqrt(t2)
+		a = math.Sin(t) / t
+		b = (1 - math.Cos(t)) / t2
+	}
+	x, y, z := omega[0], omega[1], omega[2]
+	return &Mat4{
+		1 - b*(y*y+z*z), b*x*y - a*z, b*x*z + a*y, 0,
+		b*x*y + a*z, 1 - b*(x*x+z*z), b*y*z - a*x, 0,
+		b*x*z - a*y, b*y*z + a*x, 1 - b*(x*x+y*y), 0,
+		0, 0, 0, 1,
+	}
+}
+
+// LogSO3 returns a new rotation vector, the axis scaled by the angle in
+// radians, of the rotation given by the upper-left 3x3 part of the matrix. It
+// is the inverse of ExpSO3, with the angle in [0,π]. The identity results in
+// the zero vector.
+//
+// Close to 180° the usual formula from the antisymmetric part of the matrix
+// divides by the vanishing sine of the angle. There the axis is taken from the
+// symmetric part instead. For exactly 180° both ω and -ω are valid results,
+// either of them is returned.
+func LogSO3(m *Mat4) *Vec3 {
+	v := &Vec3{m[9] - m[6], m[2] - m[8], m[4] - m[1]}
+	c := (m[0] + m[5] + m[10] - 1) / 2
+	t := math.Atan2(v.Len()/2, c)
+	switch {
+	case t < 1e-4:
+		v.Scale(0.5 * (1 + t*t/6))
+	case t < math.Pi-1e-3:
+		v.Scale(t / (2 * math.Sin(t)))
+	default:
+		// (R + Rᵀ)/2 = cos(t)*I + (1-cos(t))*a*aᵀ
+		k := 0
+		for i := 1; i < 3; i++ {
+			if m[i*5] > m[k*5] {
+				k = i
+			}
+		}
+		a := Vec3{}
+		for i := 0; i < 3; i++ {
+			a[i] = (m[i*4+k] + m[k*4+i]) / 2
+		}
+		a[k] -= c
+		a.Norm()
+		if Dot(&a, v) < 0 {
+			a.Neg()
+		}
+		a.Scale(t)
+		v = &a
+	}
+	return v
+}
@@ -0,0 +1,71 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+var expso3tests = []Vec3{
+	{0, 0, math.Pi / 2},
+	{1, 2, 3},
+	{-0.3, 0.1, 0.2},
+	{1e-5, -2e-5, 1e-5},
+}
+
+func TestExpSO3(t *testing.T) {
+	x := ExpSO3(&Vec3{0, 0, math.Pi / 2}).TransfDir(&Vec3{1, 0, 0})
+	if !x.ApproxEq(&Vec3{0, 1, 0}, eps) {
+		t.Errorf("expected '%v' but got '%v'", Vec3{0, 1, 0}, *x)
+	}
+	for _, omega := range expso3tests {
+		m := ExpSO3(&omega)
+		r := RotAxisMat(&omega, omega.Len())
+		if !m.ApproxEq(r, eps) {
+			t.Errorf("%v: expected '%v' but got '%v'", omega, *r, *m)
+		}
+	}
+}
+
+func TestExpSO3Zero(t *testing.T) {
+	if m := ExpSO3(&Vec3{0, 0, 0}); *m != *IdentityMat() {
+		t.Errorf("expected '%v' but got '%v'", *IdentityMat(), *m)
+	}
+}
+
+var logso3tests = []Vec3{
+	{0, 0, 0},
+	{1e-7, 0, -2e-7},
+	{1e-3, 2e-3, -1e-3},
+	{0, 0, math.Pi / 2},
+	{0.3, -0.5, 0.2},
+	{1, 2, 3},
+	{0, -(math.Pi - 1e-8), 0},
+}
+
+func TestLogSO3(t *testing.T) {
+	for _, omega := range logso3tests {
+		w := omega
+		if w.Len() > math.Pi {
+			// Angles beyond π come back as the equivalent shorter rotation.
+			w.Scale((w.Len() - 2*math.Pi) / w.Len())
+		}
+		l := LogSO3(ExpSO3(&omega))
+		if !l.ApproxEq(&w, 1e-7) {
+			t.Errorf("expected '%v' but got '%v'", w, *l)
+		}
+	}
+}
+
+func TestLogSO3HalfTurn(t *testing.T) {
+	axes := []Vec3{{1, 0, 0}, {0, 1, 0}, {1, 1, 0}, {-1, 2, 3}}
+	for _, a := range axes {
+		m := RotAxisMat(&a, math.Pi)
+		l := LogSO3(m)
+		if d := l.Len(); math.Abs(d-math.Pi) > eps {
+			t.Errorf("expected angle '%v' but got '%v'", math.Pi, d)
+		}
+		if r := ExpSO3(l); !r.ApproxEq(m, eps) {
+			t.Errorf("expected '%v' but got '%v'", *m, *r)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package geom
+
+import (
+	"math"
+	"sort"
+)
+
+// CatmullRom returns a new point on the uniform Catmull-Rom spline segment
+// between p1 and p2, with p0 and p3 as the neighboring control points: p1 for
+// t = 0 and p2 for t = 1. Consecutive segments of a path share their tangents,
+// so the curve passes smoothly through all interior control points. For the
+// first and last segment of a path, where there is no neighbor, pass the end
+// point twice (e.g. p0 = p1).
+func CatmullRom(p0, p1, p2, p3 *Vec3, t float64) *Vec3 {
+	t2 := t * t
+	t3 := t2 * t
+	v := Vec3{}
+	for i := range v {
+		v[i] = 0.5 * (2*p1[i] +
+			(p2[i]-p0[i])*t +
+			(2*p0[i]-5*p1[i]+4*p2[i]-p3[i])*t2 +
+			(3*p1[i]-p0[i]-3*p2[i]+p3[i])*t3)
+	}
+	return &v
+}
+
+// SplinePoint returns a new point on the Catmull-Rom spline through all the
+// given points, with t in [0,len(points)-1]: the integer part of t selects the
+// segment and the fractional part the position within it, so integer values
+// of t land exactly on the points. Values of t outside the range are clamped.
+// The end points are repeated as neighbors for the first and last segment.
+// Returns nil if there are no points or t is NaN.
+func SplinePoint(points []Vec3, t float64) *Vec3 {
+	n := len(points)
+	if n == 0 || math.IsNaN(t) {
+		return nil
+	}
+	if t >= float64(n-1) {
+		p := points[n-1]
+		return &p
+	}
+	if t <= 0 {
+		p := points[0]
+		return &p
+	}
+	i := int(t)
+	p0 := &points[i]
+	if i > 0 {
+		p0 = &points[i-1]
+	}
+	p3 := &points[i+1]
+	if i+2 < n {
+		p3 = &points[i+2]
+	}
+	return CatmullRom(p0, &points[i], &points[i+1], p3, t-float64(i))
+}
+
+// Bezier3 returns a new point on the cubic Bézier curve with control points
+// p0 to p3: p0 for t = 0 and p3 for t = 1. The curve generally does not pass
+// through p1 and p2.
+func Bezier3(p0, p1, p2, p3 *Vec3, t float64) *Vec3 {
+	s := 1 - t
+	b0 := s * s * s
+	b1 := 3 * s * s * t
+	b2 := 3 * s * t * t
+	b3 := t * t * t
+	v := Vec3{}
+	for i := range v {
+		v[i] = b0*p0[i] + b1*p1[i] + b2*p2[i] + b3*p3[i]
+	}
+	return &v
+}
+
+// Bezier3Tangent returns a new vector that is the derivative of the cubic
+// Bézier curve by t, i.e. the direction of movement along the curve. It is
+// not normalized, its length is the speed at t. At t = 0 it points from p0
+// towards p1, at t = 1 from p2 towards p3.
+func Bezier3Tangent(p0, p1, p2, p3 *Vec3, t float64) *Vec3 {
+	s := 1 - t
+	b0 := 3 * s * s
+	b1 := 6 * s * t
+	b2 := 3 * t * t
+	v := Vec3{}
+	for i := range v {
+		v[i] = b0*(p1[i]-p0[i]) + b1*(p2[i]-p1[i]) + b2*(p3[i]-p2[i])
+	}
+	return &v
+}
+
+// Spline is a Catmull-Rom spline through a sequence of points, like with
+// SplinePoint, with a table of arc lengths to move along it at constant speed.
+// Uniform steps in the spline parameter t do not result in uniform steps
+// along the curve, ArcLengthParam maps distances to parameters instead.
+type Spline struct {
+	points  []Vec3
+	samples int
+	lengths []float64
+}
+
+// NewSpline returns a new spline through the points. Each segment is sampled
+// at the given number of evenly spaced parameters to approximate the arc
+// length by the sum of the straight distances between the samples. More
+// samples result in more exact distances but a larger table. Fewer than 1
+// samples are treated as 1. Returns nil if there are no points.
+func NewSpline(points []Vec3, samples int) *Spline {
+	if len(points) == 0 {
+		return nil
+	}
+	if samples < 1 {
+		samples = 1
+	}
+	n := (len(points) - 1) * samples
+	s := &Spline{points, samples, make([]float64, n+1)}
+	p := s.Point(0)
+	for i := 1; i <= n; i++ {
+		q := s.Point(float64(i) / float64(samples))
+		s.lengths[i] = s.lengths[i-1] + Dist(p, q)
+		p = q
+	}
+	return s
+}
+
+// Point returns a new point on the spline at parameter t, see SplinePoint.
+func (s *Spline) Point(t float64) *Vec3 {
+	return SplinePoint(s.points, t)
+}
+
+// Length returns the approximate total arc length of the spline.
+func (s *Spline) Length() float64 {
+	return s.lengths[len(s.lengths)-1]
+}
+
+// ArcLengthParam returns the parameter t of the point on the spline at the
+// given distance along the curve from the first point. Between the samples of
+// the arc length table the parameter is interpolated linearly. Distances are
+// clamped to [0,Length()], NaN results in NaN.
+func (s *Spline) ArcLengthParam(dist float64) float64 {
+	if math.IsNaN(dist) {
+		return dist
+	}
+	l := s.lengths
+	if dist <= 0 || len(l) == 1 {
+		return 0
+	}
+	if dist >= l[len(l)-1] {
+		return float64(len(l)-1) / float64(s.samples)
+	}
+	i := sort.SearchFloat64s(l, dist)
+	f := (dist - l[i-1]) / (l[i] - l[i-1])
+	return (float64(i-1) + f) / float64(s.samples)
+}
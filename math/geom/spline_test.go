@@ -0,0 +1,170 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+var splinepoints = []Vec3{
+	{0, 0, 0},
+	{1, 2, 0},
+	{3, 3, 1},
+	{4, 0, -1},
+	{6, 1, 0},
+}
+
+func TestCatmullRomEnds(t *testing.T) {
+	p := splinepoints
+	for i := 1; i+2 < len(p); i++ {
+		a := CatmullRom(&p[i-1], &p[i], &p[i+1], &p[i+2], 0)
+		if !a.ApproxEq(&p[i], eps) {
+			t.Errorf("expected '%v' but got '%v'", p[i], *a)
+		}
+		b := CatmullRom(&p[i-1], &p[i], &p[i+1], &p[i+2], 1)
+		if !b.ApproxEq(&p[i+1], eps) {
+			t.Errorf("expected '%v' but got '%v'", p[i+1], *b)
+		}
+	}
+	a := CatmullRom(&p[0], &p[0], &p[1], &p[2], 0)
+	if !a.ApproxEq(&p[0], eps) {
+		t.Errorf("expected '%v' but got '%v'", p[0], *a)
+	}
+}
+
+func TestCatmullRomSmooth(t *testing.T) {
+	p := splinepoints
+	h := 1e-6
+	// The tangent at the end of one segment matches the tangent at the start
+	// of the next one.
+	e := Subbed(CatmullRom(&p[0], &p[1], &p[2], &p[3], 1), CatmullRom(&p[0], &p[1], &p[2], &p[3], 1-h))
+	s := Subbed(CatmullRom(&p[1], &p[2], &p[3], &p[4], h), CatmullRom(&p[1], &p[2], &p[3], &p[4], 0))
+	e.Scale(1 / h)
+	s.Scale(1 / h)
+	if !e.ApproxEq(s, 1e-4) {
+		t.Errorf("expected '%v' but got '%v'", *e, *s)
+	}
+}
+
+func TestSplinePoint(t *testing.T) {
+	p := splinepoints
+	for i := range p {
+		if s := SplinePoint(p, float64(i)); *s != p[i] {
+			t.Errorf("expected '%v' but got '%v'", p[i], *s)
+		}
+	}
+	s := SplinePoint(p, 1.25)
+	r := CatmullRom(&p[0], &p[1], &p[2], &p[3], 0.25)
+	if *s != *r {
+		t.Errorf("expected '%v' but got '%v'", *r, *s)
+	}
+	s = SplinePoint(p, 0.5)
+	r = CatmullRom(&p[0], &p[0], &p[1], &p[2], 0.5)
+	if *s != *r {
+		t.Errorf("expected '%v' but got '%v'", *r, *s)
+	}
+	s = SplinePoint(p, 3.5)
+	r = CatmullRom(&p[2], &p[3], &p[4], &p[4], 0.5)
+	if *s != *r {
+		t.Errorf("expected '%v' but got '%v'", *r, *s)
+	}
+}
+
+func TestSplinePointClamped(t *testing.T) {
+	p := splinepoints
+	if s := SplinePoint(p, -1); *s != p[0] {
+		t.Errorf("expected '%v' but got '%v'", p[0], *s)
+	}
+	if s := SplinePoint(p, 10); *s != p[4] {
+		t.Errorf("expected '%v' but got '%v'", p[4], *s)
+	}
+	if s := SplinePoint(p[:1], 0.5); *s != p[0] {
+		t.Errorf("expected '%v' but got '%v'", p[0], *s)
+	}
+	if s := SplinePoint(nil, 0); s != nil {
+		t.Errorf("expected '%v' but got '%v'", nil, s)
+	}
+	if s := SplinePoint(p, math.NaN()); s != nil {
+		t.Errorf("expected '%v' but got '%v'", nil, s)
+	}
+}
+
+func TestBezier3Ends(t *testing.T) {
+	p := splinepoints
+	a := Bezier3(&p[0], &p[1], &p[2], &p[3], 0)
+	if *a != p[0] {
+		t.Errorf("expected '%v' but got '%v'", p[0], *a)
+	}
+	b := Bezier3(&p[0], &p[1], &p[2], &p[3], 1)
+	if *b != p[3] {
+		t.Errorf("expected '%v' but got '%v'", p[3], *b)
+	}
+	m := Bezier3(&p[0], &p[1], &p[2], &p[3], 0.5)
+	r := Vec3{2, 1.875, 0.25}
+	if !m.ApproxEq(&r, eps) {
+		t.Errorf("expected '%v' but got '%v'", r, *m)
+	}
+}
+
+func TestBezier3Tangent(t *testing.T) {
+	p := splinepoints
+	d := Bezier3Tangent(&p[0], &p[1], &p[2], &p[3], 0)
+	r := Subbed(&p[1], &p[0])
+	r.Scale(3)
+	if !d.ApproxEq(r, eps) {
+		t.Errorf("expected '%v' but got '%v'", *r, *d)
+	}
+	h := 1e-6
+	n := Subbed(Bezier3(&p[0], &p[1], &p[2], &p[3], 0.3+h), Bezier3(&p[0], &p[1], &p[2], &p[3], 0.3))
+	n.Scale(1 / h)
+	d = Bezier3Tangent(&p[0], &p[1], &p[2], &p[3], 0.3)
+	if !d.ApproxEq(n, 1e-4) {
+		t.Errorf("expected '%v' but got '%v'", *n, *d)
+	}
+}
+
+func TestSplineArcLengthParam(t *testing.T) {
+	s := NewSpline(splinepoints, 100)
+	l := s.Length()
+	if a := s.ArcLengthParam(0); a != 0 {
+		t.Errorf("expected '%v' but got '%v'", 0, a)
+	}
+	if a := s.ArcLengthParam(l); a != 4 {
+		t.Errorf("expected '%v' but got '%v'", 4, a)
+	}
+	if a := s.ArcLengthParam(2 * l); a != 4 {
+		t.Errorf("expected '%v' but got '%v'", 4, a)
+	}
+	if a := s.ArcLengthParam(math.NaN()); !math.IsNaN(a) {
+		t.Errorf("expected '%v' but got '%v'", math.NaN(), a)
+	}
+	// Sample a gently curved path with uneven point spacing, where the
+	// straight distances between close points approximate the arc length.
+	var points []Vec3
+	for _, a := range []float64{0, 0.2, 0.3, 0.6, 0.8, 1.2, 1.5} {
+		points = append(points, Vec3{5 * math.Cos(a), 5 * math.Sin(a), a})
+	}
+	s = NewSpline(points, 100)
+	n := 40
+	step := s.Length() / float64(n)
+	p := s.Point(0)
+	for i := 1; i <= n; i++ {
+		q := s.Point(s.ArcLengthParam(float64(i) * step))
+		if d := Dist(p, q); math.Abs(d-step) > 0.02*step {
+			t.Errorf("step %v: expected spacing '%v' but got '%v'", i, step, d)
+		}
+		p = q
+	}
+}
+
+func TestSplineSinglePoint(t *testing.T) {
+	s := NewSpline(splinepoints[:1], 10)
+	if l := s.Length(); l != 0 {
+		t.Errorf("expected '%v' but got '%v'", 0, l)
+	}
+	if a := s.ArcLengthParam(1); a != 0 {
+		t.Errorf("expected '%v' but got '%v'", 0, a)
+	}
+	if s := NewSpline(nil, 10); s != nil {
+		t.Errorf("expected '%v' but got '%v'", nil, s)
+	}
+}
@@ -0,0 +1,43 @@
+package geom
+
+// Barycentric returns the barycentric coordinates of point p relative to the
+// triangle abc, such that u*a + v*b + w*c is the point p projected onto the
+// plane of the triangle and u + v + w = 1. All coordinates are within [0,1]
+// if and only if the projected point lies inside the triangle.
+//
+// A degenerate triangle (with no area) has no defined barycentric coordinates.
+// In that case (1, 0, 0) is returned, i.e. everything is attributed to a.
+func Barycentric(p, a, b, c *Vec3) (u, v, w float64) {
+	e0 := Subbed(b, a)
+	e1 := Subbed(c, a)
+	e2 := Subbed(p, a)
+	d00 := Dot(e0, e0)
+	d01 := Dot(e0, e1)
+	d11 := Dot(e1, e1)
+	d20 := Dot(e2, e0)
+	d21 := Dot(e2, e1)
+	det := d00*d11 - d01*d01
+	if det == 0 {
+		return 1, 0, 0
+	}
+	v = (d11*d20 - d01*d21) / det
+	w = (d00*d21 - d01*d20) / det
+	return 1 - v - w, v, w
+}
+
+// TriArea returns the area of the triangle abc.
+func TriArea(a, b, c *Vec3) float64 {
+	return Cross(Subbed(b, a), Subbed(c, a)).Len() / 2
+}
+
+// TriNormal returns a new unit vector perpendicular to the triangle abc, the
+// normalized cross product of the edges b-a and c-a. It points towards the
+// side from which a, b and c appear in counter-clockwise order, so for an
+// outward-facing normal the vertices of a face must be ordered
+// counter-clockwise when seen from outside. For a degenerate triangle (with
+// no area) the zero vector is returned.
+func TriNormal(a, b, c *Vec3) *Vec3 {
+	n := Cross(Subbed(b, a), Subbed(c, a))
+	n.Norm()
+	return n
+}
@@ -0,0 +1,69 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+var barycentrictests = []struct {
+	p       Vec3
+	u, v, w float64
+}{
+	{Vec3{1, 1, 0}, 1.0 / 3, 1.0 / 3, 1.0 / 3},
+	{Vec3{1, 1, 5}, 1.0 / 3, 1.0 / 3, 1.0 / 3},
+	{Vec3{0, 0, 0}, 1, 0, 0},
+	{Vec3{3, 0, 0}, 0, 1, 0},
+	{Vec3{0, 1.5, 0}, 0.5, 0, 0.5},
+	{Vec3{3, 3, 0}, -1, 1, 1},
+}
+
+func TestBarycentric(t *testing.T) {
+	a := Vec3{0, 0, 0}
+	b := Vec3{3, 0, 0}
+	c := Vec3{0, 3, 0}
+	for _, test := range barycentrictests {
+		u, v, w := Barycentric(&test.p, &a, &b, &c)
+		if math.Abs(u-test.u) > eps || math.Abs(v-test.v) > eps || math.Abs(w-test.w) > eps {
+			t.Errorf("%v: expected '%v' but got '%v'", test.p, []float64{test.u, test.v, test.w}, []float64{u, v, w})
+		}
+	}
+}
+
+func TestBarycentricDegenerate(t *testing.T) {
+	a := Vec3{0, 0, 0}
+	b := Vec3{1, 1, 1}
+	c := Vec3{2, 2, 2}
+	u, v, w := Barycentric(&Vec3{1, 0, 0}, &a, &b, &c)
+	if u != 1 || v != 0 || w != 0 {
+		t.Errorf("expected '%v' but got '%v'", []float64{1, 0, 0}, []float64{u, v, w})
+	}
+}
+
+var triareatests = []struct {
+	a, b, c Vec3
+	area    float64
+	normal  Vec3
+}{
+	{Vec3{0, 0, 0}, Vec3{1, 0, 0}, Vec3{0, 1, 0}, 0.5, Vec3{0, 0, 1}},
+	{Vec3{0, 0, 0}, Vec3{0, 1, 0}, Vec3{1, 0, 0}, 0.5, Vec3{0, 0, -1}},
+	{Vec3{1, 1, 1}, Vec3{1, 1, 5}, Vec3{4, 1, 1}, 6, Vec3{0, 1, 0}},
+	{Vec3{0, 0, 0}, Vec3{1, 1, 1}, Vec3{2, 2, 2}, 0, Vec3{0, 0, 0}},
+}
+
+func TestTriArea(t *testing.T) {
+	for _, test := range triareatests {
+		a := TriArea(&test.a, &test.b, &test.c)
+		if math.Abs(a-test.area) > eps {
+			t.Errorf("expected '%v' but got '%v'", test.area, a)
+		}
+	}
+}
+
+func TestTriNormal(t *testing.T) {
+	for _, test := range triareatests {
+		n := TriNormal(&test.a, &test.b, &test.c)
+		if !n.ApproxEq(&test.normal, eps) {
+			t.Errorf("expected '%v' but got '%v'", test.normal, *n)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package geom
+
+import (
+	"math"
+)
+
+// VoxelWalk steps along a ray through the cells of a regular grid, visiting
+// every cell the ray passes through in order, using the algorithm of Amanatides
+// and Woo. Cells are cubes of the same size with one corner at the origin, the
+// cell (i, j, k) covers the points from (i, j, k)*cellSize up to
+// (i+1, j+1, k+1)*cellSize.
+type VoxelWalk struct {
+	cell    [3]int
+	step    [3]int
+	tMax    [3]float64
+	tDelta  [3]float64
+	moving  bool
+	started bool
+}
+
+// NewVoxelWalk returns a new walk along the ray through the grid with the
+// given cell size, which must be greater than 0. The walk starts with the cell
+// containing the origin of the ray, or if the origin lies on the boundary
+// between cells, with the one the ray moves into. It is unbounded, the caller
+// decides when to stop, e.g. after a maximum t or when a cell is occupied.
+func NewVoxelWalk(r *Ray, cellSize float64) *VoxelWalk {
+	w := &VoxelWalk{}
+	for i := 0; i < 3; i++ {
+		o, d := r.Origin[i], r.Dir[i]
+		c := math.Floor(o / cellSize)
+		switch {
+		case d > 0:
+			w.step[i] = 1
+			w.tMax[i] = ((c+1)*cellSize - o) / d
+			w.tDelta[i] = cellSize / d
+			w.moving = true
+		case d < 0:
+			// An origin on the lower face of a cell starts in the
+			// cell below, which the ray moves into.
+			c = math.Ceil(o/cellSize) - 1
+			w.step[i] = -1
+			w.tMax[i] = (c*cellSize - o) / d
+			w.tDelta[i] = -cellSize / d
+			w.moving = true
+		default:
+			w.tMax[i] = math.Inf(1)
+			w.tDelta[i] = math.Inf(1)
+		}
+		w.cell[i] = int(c)
+	}
+	return w
+}
+
+// Next returns the next cell the ray passes through and the parameter t at
+// which the ray enters it, in multiples of the length of the ray direction.
+// The first call returns the cell containing the origin with t = 0. The
+// returned bool is false if there is no next cell, which only happens for a
+// ray with a direction of length 0 after its first cell.
+func (w *VoxelWalk) Next() (cell [3]int, t float64, ok bool) {
+	if !w.started {
+		w.started = true
+		return w.cell, 0, true
+	}
+	if !w.moving {
+		return w.cell, 0, false
+	}
+	a := 0
+	if w.tMax[1] < w.tMax[a] {
+		a = 1
+	}
+	if w.tMax[2] < w.tMax[a] {
+		a = 2
+	}
+	t = w.tMax[a]
+	w.cell[a] += w.step[a]
+	w.tMax[a] += w.tDelta[a]
+	return w.cell, t, true
+}
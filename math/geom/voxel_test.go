@@ -0,0 +1,65 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+func TestVoxelWalkX(t *testing.T) {
+	r := Ray{Vec3{0.5, 0.5, 0.5}, Vec3{1, 0, 0}}
+	w := NewVoxelWalk(&r, 1)
+	last := -1.0
+	for i := 0; i < 5; i++ {
+		c, tc, ok := w.Next()
+		if !ok {
+			t.Fatalf("expected '%v' but got '%v'", true, ok)
+		}
+		if rc := [3]int{i, 0, 0}; c != rc {
+			t.Errorf("expected '%v' but got '%v'", rc, c)
+		}
+		if tc <= last && i > 0 {
+			t.Errorf("expected t greater than '%v' but got '%v'", last, tc)
+		}
+		if rt := math.Max(0, float64(i)-0.5); math.Abs(tc-rt) > eps {
+			t.Errorf("expected '%v' but got '%v'", rt, tc)
+		}
+		last = tc
+	}
+}
+
+func TestVoxelWalkDiagonal(t *testing.T) {
+	r := Ray{Vec3{0.5, 0.25, -0.5}, Vec3{-1, 2, 0}}
+	w := NewVoxelWalk(&r, 0.5)
+	cells := [][3]int{{0, 0, -1}, {0, 1, -1}, {0, 2, -1}, {-1, 2, -1}, {-1, 3, -1}, {-1, 4, -1}}
+	ts := []float64{0, 0.125, 0.375, 0.5, 0.625, 0.875}
+	for i := range cells {
+		c, tc, ok := w.Next()
+		if !ok || c != cells[i] || math.Abs(tc-ts[i]) > eps {
+			t.Errorf("expected '%v' but got '%v'", []interface{}{cells[i], ts[i], true}, []interface{}{c, tc, ok})
+		}
+	}
+}
+
+func TestVoxelWalkFromBoundary(t *testing.T) {
+	r := Ray{Vec3{-0.5, 2.5, 1}, Vec3{0, 0, -1}}
+	w := NewVoxelWalk(&r, 1)
+	cells := [][3]int{{-1, 2, 0}, {-1, 2, -1}, {-1, 2, -2}}
+	ts := []float64{0, 1, 2}
+	for i := range cells {
+		c, tc, ok := w.Next()
+		if !ok || c != cells[i] || tc != ts[i] {
+			t.Errorf("expected '%v' but got '%v'", []interface{}{cells[i], ts[i], true}, []interface{}{c, tc, ok})
+		}
+	}
+}
+
+func TestVoxelWalkZeroDir(t *testing.T) {
+	r := Ray{Vec3{1.5, 2.5, 3.5}, Vec3{0, 0, 0}}
+	w := NewVoxelWalk(&r, 1)
+	if c, _, ok := w.Next(); !ok || c != [3]int{1, 2, 3} {
+		t.Errorf("expected '%v' but got '%v'", [3]int{1, 2, 3}, c)
+	}
+	if _, _, ok := w.Next(); ok {
+		t.Errorf("expected '%v' but got '%v'", false, ok)
+	}
+}
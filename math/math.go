@@ -32,3 +32,30 @@ func Mini(a, b int) int {
 		return b
 	}
 }
+
+// smoothParam returns x mapped from [edge0,edge1] to [0,1] and clamped. If
+// both edges are equal it works as a step function at the edge.
+func smoothParam(edge0, edge1, x float64) float64 {
+	if edge0 == edge1 {
+		if x < edge0 {
+			return 0
+		}
+		return 1
+	}
+	t := (x - edge0) / (edge1 - edge0)
+	return math.Max(0, math.Min(1, t))
+}
+
+// Smoothstep returns a smooth transition from 0 to 1 as x goes from edge0 to
+// edge1, using the curve 3t²-2t³. It is 0 below edge0 and 1 above edge1.
+func Smoothstep(edge0, edge1, x float64) float64 {
+	t := smoothParam(edge0, edge1, x)
+	return t * t * (3 - 2*t)
+}
+
+// Smootherstep works like Smoothstep, but uses the curve 6t⁵-15t⁴+10t³ which
+// also has zero second derivatives at the edges.
+func Smootherstep(edge0, edge1, x float64) float64 {
+	t := smoothParam(edge0, edge1, x)
+	return t * t * t * (t*(6*t-15) + 10)
+}
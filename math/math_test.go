@@ -82,3 +82,36 @@ func TestMaxi(t *testing.T) {
 		}
 	}
 }
+
+var smoothtests = []struct {
+	edge0, edge1, x  float64
+	smooth, smoother float64
+}{
+	{0, 1, 0, 0, 0},
+	{0, 1, 1, 1, 1},
+	{0, 1, 0.5, 0.5, 0.5},
+	{2, 4, 3, 0.5, 0.5},
+	{2, 4, 1, 0, 0},
+	{2, 4, 5, 1, 1},
+	{0, 1, 0.25, 0.15625, 0.103515625},
+	{1, 1, 0, 0, 0},
+	{1, 1, 1, 1, 1},
+}
+
+func TestSmoothstep(t *testing.T) {
+	for _, test := range smoothtests {
+		s := Smoothstep(test.edge0, test.edge1, test.x)
+		if s != test.smooth {
+			t.Errorf("expected '%v' but got '%v'", test.smooth, s)
+		}
+	}
+}
+
+func TestSmootherstep(t *testing.T) {
+	for _, test := range smoothtests {
+		s := Smootherstep(test.edge0, test.edge1, test.x)
+		if s != test.smoother {
+			t.Errorf("expected '%v' but got '%v'", test.smoother, s)
+		}
+	}
+}